@@ -1,31 +1,77 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/captcha"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/channel"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/mail"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/repo"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/weatherapi"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/config"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/health"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/formtoken"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	readinessTimeout          = 3 * time.Second
+	weatherProviderMaxFailure = 3
+	mailJobMaxStaleness       = 2 * time.Hour
+	mailJobCatchUpCheck       = "*/5 * * * *"
 )
 
 func main() {
-	pkg.InitLogger()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	bootstrapLogger := pkg.NewBootstrapLogger()
+	cfg := config.LoadConfig(bootstrapLogger)
+
+	logging := newLogging(cfg, bootstrapLogger)
 	defer func() {
-		if err := pkg.Logger.Sync(); err != nil {
+		if err := logging.Sync(); err != nil {
 			fmt.Printf("failed to sync logger: %v\n", err)
 		}
 	}()
-	cfg := config.LoadConfig()
+	logger := logging.Logger("main")
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Warn("failed to flush traces", zap.Error(err))
+		}
+	}()
 
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
@@ -33,34 +79,142 @@ func main() {
 	)
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
-		pkg.Logger.Fatal("failed to connect database", zap.Error(err))
+		logger.Fatal("failed to connect database", zap.Error(err))
 	}
 
-	subscriptionRepo := repo.NewPostgresRepo(db)
-	smtpMailer := mail.NewSMTPMailer(
-		cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPFrom, cfg.BaseURL,
-	)
-	weatherProvider := weatherapi.NewWeatherAPIProvider(cfg.WeatherAPIKey)
-	subService := service.NewSubscriptionService(subscriptionRepo, smtpMailer)
-	weatherService := service.NewWeatherService(weatherProvider)
+	subscriptionRepo := repo.NewPostgresRepo(db, logging.Logger("repo"))
+	if err := metrics.RegisterSubscriptionsCollector(subscriptionRepo, metrics.DefaultSubscriptionsTTL, logging.Logger("metrics")); err != nil {
+		logger.Fatal("failed to register subscriptions collector", zap.Error(err))
+	}
+	mailer, mailCheckName, mailCheck, err := newMailer(cfg, logging.Logger("mailer"))
+	if err != nil {
+		logger.Fatal("failed to configure mail provider", zap.String("provider", cfg.MailProvider), zap.Error(err))
+	}
+	weatherProvider := weatherapi.NewWeatherAPIProvider(cfg.WeatherAPIKey, logging.Logger("weatherapi"))
+	notifiers := newNotifiers(cfg, mailer, logging)
+	subService := service.NewSubscriptionService(subscriptionRepo, mailer, logging.Logger("service"))
+	subService.Channels = notifiers
+	suppressionService := service.NewSuppressionService(repo.NewPostgresSuppressionRepo(db, logging.Logger("repo")), logging.Logger("service"))
+	subService.Suppressions = suppressionService
+	weatherService := service.NewCachedWeatherService(weatherProvider, cfg.WeatherCacheTTL, cfg.WeatherCacheSize)
 
-	c := cron.New()
-	if _, err := c.AddFunc("0 * * * *", func() {
-		pkg.Logger.Info("Starting scheduled weather mail job...")
-		if err := scheduler.MailJob(subService, weatherService); err != nil {
-			pkg.Logger.Error("Mail job failed", zap.Error(err))
+	jobRunRepo := repo.NewPostgresJobRunRepo(db, logging.Logger("repo"))
+	deliveryRepo := repo.NewPostgresDeliveryRepo(db, logging.Logger("repo"))
+	outboxRepo := repo.NewPostgresOutboxRepo(db, logging.Logger("repo"))
+
+	dispatcher := outbox.NewDispatcher(outboxRepo, mailer, deliveryRepo, outbox.Config{
+		PollInterval: cfg.OutboxPollInterval,
+		BatchSize:    cfg.OutboxBatchSize,
+		MaxAttempts:  cfg.OutboxMaxAttempts,
+		BaseBackoff:  cfg.OutboxBaseBackoff,
+		MaxBackoff:   cfg.OutboxMaxBackoff,
+		Recipients:   subscriptionRepo,
+		Suppressions: suppressionService,
+		Notifiers:    notifiers,
+	}, logging.Logger("outbox"))
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	defer stopDispatcher()
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(dispatcherCtx)
+	}()
+
+	schedulerLogger := logging.Logger("scheduler")
+	mailJobTracker := scheduler.NewTracker()
+	mailJobOptions := scheduler.Options{
+		Concurrency: cfg.MailJobConcurrency,
+		BatchSize:   cfg.MailJobBatchSize,
+		WorkerID:    scheduler.DefaultWorkerID(),
+		ClaimLease:  cfg.MailJobClaimLease,
+		BaseURL:     cfg.BaseURL,
+		History:     jobRunRepo,
+		Ledger:      deliveryRepo,
+		Channels:    notifiers,
+	}
+	var mailJobLocker scheduler.Locker
+	if cfg.MailJobLock {
+		mailJobLocker = repo.NewAdvisoryLocker(db, logging.Logger("repo"))
+	}
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	runMailJob := func() {
+		schedulerLogger.Info("Starting scheduled weather mail job...")
+		var summary *scheduler.Summary
+		err := mailJobTracker.Run(func() error {
+			err := scheduler.RunLocked(jobCtx, mailJobLocker, scheduler.MailJobLockName, func() error {
+				var err error
+				summary, err = scheduler.MailJob(jobCtx, subService, weatherService, mailJobOptions, schedulerLogger)
+				return err
+			})
+			if errors.Is(err, scheduler.ErrLocked) {
+				schedulerLogger.Info("Mail job skipped, another instance holds the lock")
+				return nil
+			}
+			return err
+		})
+		if summary == nil && err == nil {
+			return
+		}
+		if err != nil {
+			schedulerLogger.Error("Mail job failed", zap.Error(err), zap.Any("summary", summary))
 		} else {
-			pkg.Logger.Info("Weather mail job completed successfully")
+			schedulerLogger.Info("Weather mail job completed successfully", zap.Any("summary", summary))
+		}
+	}
+	mailJobSchedule, err := cron.ParseStandard(scheduler.MailJobSpec)
+	if err != nil {
+		logger.Fatal("failed to parse mail job schedule", zap.Error(err))
+	}
+	c := cron.New()
+	c.Schedule(mailJobSchedule, cron.FuncJob(runMailJob))
+	if cfg.MailJobCatchUp > 0 {
+		catchUpPolicy := scheduler.CatchUpPolicy{
+			Schedule: mailJobSchedule,
+			Grace:    cfg.MailJobCatchUp,
+			Lease:    cfg.MailJobClaimLease,
+		}
+		catchUp := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+			missed, err := scheduler.CatchUp(ctx, jobRunRepo, time.Now(), catchUpPolicy)
+			if err != nil {
+				schedulerLogger.Warn("Failed to check for missed mail job runs", zap.Error(err))
+				return
+			}
+			if len(missed) == 0 {
+				return
+			}
+			schedulerLogger.Info("Mail job runs were missed, catching up", zap.Times("scheduled_at", missed))
+			runMailJob()
+		}))
+		// The check on startup covers ticks missed while the service was down,
+		// the periodic one ticks missed while it was up: the lock was held by
+		// an instance whose run then failed, or the previous run was still
+		// going at the top of the hour.
+		c.Schedule(scheduler.Once(time.Now()), catchUp)
+		if _, err := c.AddJob(mailJobCatchUpCheck, catchUp); err != nil {
+			logger.Fatal("failed to add cron job", zap.Error(err))
 		}
-	}); err != nil {
-		pkg.Logger.Fatal("failed to add cron job", zap.Error(err))
 	}
 	c.Start()
 
-	subHandler := handler.NewSubscriptionHandler(subService, weatherService)
+	readiness := health.NewRegistry(readinessTimeout)
+	readiness.Register("database", true, health.DatabaseCheck(db))
+	readiness.Register(mailCheckName, true, mailCheck)
+	readiness.Register("weather_provider", false, health.WeatherProviderCheck(weatherProvider, weatherProviderMaxFailure))
+	readiness.Register("scheduler", false, health.SchedulerCheck(mailJobTracker, time.Now(), mailJobMaxStaleness))
+
+	httpLogger := logging.Logger("http")
+	subHandler := handler.NewSubscriptionHandler(subService, weatherService, httpLogger)
+	subHandler.BotGuard = newBotGuard(cfg, logger, logging.Logger("captcha"))
 
-	r := gin.Default()
+	r := gin.New()
 
+	r.Use(otelgin.Middleware(cfg.TracingServiceName))
+	r.Use(middleware.RequestID())
+	r.Use(middleware.AccessLog(logging.Logger("access")))
+	// Metrics wrap Recovery so requests that panic are still counted as 500s.
+	r.Use(metrics.GinMiddleware())
+	r.Use(middleware.Recovery(httpLogger))
 	r.Use(cors.Default())
 	r.Static("/static", "./web/static")
 
@@ -74,9 +228,192 @@ func main() {
 	})
 
 	handler.RegisterRoutes(r, subHandler)
+	handler.RegisterEmailEventRoutes(r, cfg.EmailEventsToken, handler.NewEmailEventsHandler(suppressionService, httpLogger))
+	handler.RegisterHealthRoutes(r, handler.NewHealthHandler(readiness))
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	admin := handler.RegisterAdminRoutes(r, cfg.AdminToken, handler.NewAdminHandler(logging, httpLogger))
+	handler.RegisterJobRoutes(admin, handler.NewJobHandler(jobRunRepo, httpLogger))
+	handler.RegisterOutboxRoutes(admin, handler.NewOutboxHandler(outboxRepo, httpLogger))
+	mailJobTrigger := scheduler.NewTrigger(subService, weatherService, mailJobOptions, mailJobLocker, schedulerLogger)
+	handler.RegisterTriggerRoutes(admin, handler.NewTriggerHandler(mailJobTrigger, httpLogger))
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("API server running", zap.String("addr", cfg.HTTPAddr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutdown signal received")
+	case err := <-serverErr:
+		if err != nil {
+			logger.Error("failed to run server", zap.Error(err))
+		}
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server did not drain in time", zap.Error(err))
+	} else {
+		logger.Info("HTTP server stopped")
+	}
+
+	stopScheduler(c, cancelJobs, cfg.JobShutdownTimeout, schedulerLogger)
+
+	// The dispatcher finishes the email it is sending; the rest stay queued.
+	stopDispatcher()
+	select {
+	case <-dispatcherDone:
+		logger.Info("outbox dispatcher stopped")
+	case <-time.After(cfg.ShutdownTimeout):
+		logger.Error("outbox dispatcher did not stop in time")
+	}
+	mailer.Close()
+	logger.Info("shutdown complete")
+}
+
+// stopScheduler stops cron from starting new runs and waits for a running job
+// to finish. When timeout passes first, the job is cancelled so it stops at
+// the next subscriber boundary, and we wait for that checkpoint.
+func stopScheduler(c *cron.Cron, cancelJobs context.CancelFunc, timeout time.Duration, logger *zap.Logger) {
+	done := c.Stop().Done()
+	select {
+	case <-done:
+		logger.Info("scheduler stopped")
+		return
+	case <-time.After(timeout):
+		logger.Warn("mail job still running at shutdown deadline, interrupting", zap.Duration("timeout", timeout))
+	}
+
+	cancelJobs()
+	select {
+	case <-done:
+		logger.Info("scheduler stopped at checkpoint")
+	case <-time.After(timeout):
+		logger.Error("mail job did not reach a checkpoint, exiting anyway")
+	}
+}
+
+func newLogging(cfg *config.Config, bootstrapLogger *zap.Logger) *pkg.Logging {
+	componentLevels, err := pkg.ParseComponentLevels(cfg.LogComponentLevels)
+	if err != nil {
+		bootstrapLogger.Fatal("invalid LOG_LEVELS", zap.Error(err))
+	}
+	logging, err := pkg.NewLogging(pkg.LogConfig{
+		Level:           cfg.LogLevel,
+		Format:          cfg.LogFormat,
+		Sampling:        cfg.LogSampling,
+		ComponentLevels: componentLevels,
+	})
+	if err != nil {
+		bootstrapLogger.Fatal("failed to configure logging", zap.Error(err))
+	}
+	return logging
+}
+
+func newBotGuard(cfg *config.Config, logger, captchaLogger *zap.Logger) *handler.BotGuard {
+	secret := []byte(cfg.FormTokenSecret)
+	if len(secret) == 0 {
+		logger.Warn("FORM_TOKEN_SECRET not set, generating an ephemeral secret; form tokens will not survive restarts or work across replicas")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Fatal("failed to generate form token secret", zap.Error(err))
+		}
+	}
+	signer, err := formtoken.NewSigner(secret)
+	if err != nil {
+		logger.Fatal("failed to create form token signer", zap.Error(err))
+	}
+	guard := handler.NewBotGuard(signer, cfg.FormMinFillTime, cfg.FormTokenMaxAge)
+
+	switch cfg.CaptchaProvider {
+	case "":
+	case "hcaptcha":
+		verifier := captcha.NewHCaptchaVerifier(cfg.CaptchaSecret, captchaLogger)
+		if cfg.CaptchaVerifyURL != "" {
+			verifier = captcha.NewSiteVerifyVerifier(cfg.CaptchaVerifyURL, cfg.CaptchaSecret, captchaLogger)
+		}
+		guard.WithCaptcha("hcaptcha", cfg.CaptchaSiteKey, verifier)
+	case "turnstile":
+		verifier := captcha.NewTurnstileVerifier(cfg.CaptchaSecret, captchaLogger)
+		if cfg.CaptchaVerifyURL != "" {
+			verifier = captcha.NewSiteVerifyVerifier(cfg.CaptchaVerifyURL, cfg.CaptchaSecret, captchaLogger)
+		}
+		guard.WithCaptcha("turnstile", cfg.CaptchaSiteKey, verifier)
+	default:
+		logger.Fatal("unknown captcha provider", zap.String("provider", cfg.CaptchaProvider))
+	}
+	return guard
+}
+
+// newMailer builds the mailer for MAIL_PROVIDER and the readiness check of the
+// SMTP relay or email API behind it. The check keeps the "smtp" key for SMTP,
+// so existing probes see no change, and is "mail_api" for every HTTP provider.
+func newMailer(cfg *config.Config, logger *zap.Logger) (*mail.Mailer, string, health.Check, error) {
+	var transport interface {
+		mail.Transport
+		Addr() string
+	}
+	var err error
+	switch cfg.MailProvider {
+	case mail.ProviderSMTP:
+		pool, err := mail.NewPool(mail.Config{
+			Host:        cfg.SMTPHost,
+			Port:        cfg.SMTPPort,
+			Username:    cfg.SMTPUser,
+			Password:    cfg.SMTPPass,
+			TLSMode:     cfg.SMTPTLSMode,
+			CAFile:      cfg.SMTPCAFile,
+			SkipVerify:  cfg.SMTPSkipVerify,
+			PoolSize:    cfg.SMTPPoolSize,
+			IdleTimeout: cfg.SMTPIdleTimeout,
+			Timeout:     cfg.SMTPTimeout,
+		}, logger)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return mail.NewSMTPMailer(pool, cfg.SMTPFrom, cfg.BaseURL, logger), "smtp", health.SMTPCheck(cfg.SMTPHost, cfg.SMTPPort), nil
+	case mail.ProviderSendGrid:
+		transport, err = mail.NewSendGridTransport(cfg.MailAPIURL, cfg.MailAPIKey)
+	case mail.ProviderMailgun:
+		transport, err = mail.NewMailgunTransport(cfg.MailAPIURL, cfg.MailgunDomain, cfg.MailAPIKey)
+	case mail.ProviderSES:
+		transport, err = mail.NewSESTransport(cfg.MailAPIURL, mail.SESCredentials{
+			Region:          cfg.AWSRegion,
+			AccessKeyID:     cfg.AWSAccessKeyID,
+			SecretAccessKey: cfg.AWSSecretAccessKey,
+		})
+	default:
+		return nil, "", nil, fmt.Errorf("unknown mail provider %q", cfg.MailProvider)
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return mail.NewMailer(transport, cfg.SMTPFrom, cfg.BaseURL, logger), "mail_api", health.DialCheck(transport.Addr()), nil
+}
 
-	pkg.Logger.Info("API server running", zap.String("addr", ":8080"))
-	if err := r.Run(":8080"); err != nil {
-		pkg.Logger.Fatal("failed to run server", zap.Error(err))
+// newNotifiers registers email and every chat channel that is configured.
+// Telegram needs a bot token; the webhook based channels need nothing.
+func newNotifiers(cfg *config.Config, mailer notify.WeatherMailer, logging *pkg.Logging) *notify.Registry {
+	logger := logging.Logger("notify")
+	notifiers := notify.NewRegistry(cfg.BaseURL)
+	notifiers.Register(model.ChannelEmail, notify.MailNotifier{Mailer: mailer})
+	if cfg.TelegramBotToken != "" {
+		notifiers.Register(model.ChannelTelegram, channel.NewTelegramNotifier(cfg.TelegramAPIURL, cfg.TelegramBotToken, logger))
 	}
+	notifiers.Register(model.ChannelSlack, channel.NewSlackNotifier(cfg.SlackWebhookBaseURL, logger))
+	notifiers.Register(model.ChannelDiscord, channel.NewDiscordNotifier(cfg.DiscordWebhookBaseURL, logger))
+	notifiers.Register(model.ChannelWebhook, channel.NewWebhookNotifier(cfg.WebhookBaseURL, logger))
+	return notifiers
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/repo"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/weatherapi"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/config"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// mailjob runs the weather mail job once, outside the hourly schedule, and
+// prints the run summary as JSON.
+func main() {
+	city := flag.String("city", "", "only email subscribers of this city")
+	subscriptionID := flag.Int64("subscription", 0, "only email this subscription")
+	dryRun := flag.Bool("dry-run", false, "render the emails that would be queued without queueing them")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger := pkg.NewBootstrapLogger()
+	cfg := config.LoadConfig(logger)
+
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort, cfg.DB_SSLMODE,
+	)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		logger.Fatal("failed to connect database", zap.Error(err))
+	}
+
+	// Emails are queued in the outbox and sent by the server's dispatcher, so
+	// the job needs no mailer here.
+	subService := service.NewSubscriptionService(repo.NewPostgresRepo(db, logger), nil, logger)
+	weatherService := service.NewCachedWeatherService(weatherapi.NewWeatherAPIProvider(cfg.WeatherAPIKey, logger), cfg.WeatherCacheTTL, cfg.WeatherCacheSize)
+
+	var locker scheduler.Locker
+	if cfg.MailJobLock {
+		locker = repo.NewAdvisoryLocker(db, logger)
+	}
+	trigger := scheduler.NewTrigger(subService, weatherService, scheduler.Options{
+		Concurrency: cfg.MailJobConcurrency,
+		BatchSize:   cfg.MailJobBatchSize,
+		WorkerID:    scheduler.DefaultWorkerID(),
+		ClaimLease:  cfg.MailJobClaimLease,
+		BaseURL:     cfg.BaseURL,
+		History:     repo.NewPostgresJobRunRepo(db, logger),
+		Ledger:      repo.NewPostgresDeliveryRepo(db, logger),
+	}, locker, logger)
+
+	summary, err := trigger.Run(ctx, service.Scope{City: *city, SubscriptionID: *subscriptionID}, *dryRun)
+	if summary != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summary); err != nil {
+			logger.Error("failed to print summary", zap.Error(err))
+		}
+	}
+	if errors.Is(err, scheduler.ErrLocked) {
+		logger.Fatal("mail job is already running on another instance")
+	}
+	if err != nil {
+		logger.Fatal("mail job failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/simulation"
+)
+
+// schedsim runs the mail scheduler over virtual time against in-memory fakes
+// and prints every email it would send and when.
+func main() {
+	start := flag.String("start", time.Now().UTC().Truncate(24*time.Hour).Format(time.RFC3339), "simulation start (RFC3339)")
+	days := flag.Int("days", 7, "number of days to simulate")
+	hourly := flag.Int("hourly", 1, "number of hourly subscribers")
+	daily := flag.Int("daily", 1, "number of daily subscribers")
+	grace := flag.Duration("grace", 45*time.Minute, "catch-up grace after an outage, 0 disables catch-up")
+	var outages []simulation.Outage
+	flag.Func("outage", "downtime as START/DURATION, e.g. 2025-06-02T10:00:00Z/20m (repeatable)", func(v string) error {
+		from, length, ok := strings.Cut(v, "/")
+		if !ok {
+			return fmt.Errorf("expected START/DURATION, got %q", v)
+		}
+		at, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return err
+		}
+		d, err := time.ParseDuration(length)
+		if err != nil {
+			return err
+		}
+		outages = append(outages, simulation.Outage{From: at, To: at.Add(d)})
+		return nil
+	})
+	flag.Parse()
+
+	from, err := time.Parse(time.RFC3339, *start)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -start: %v\n", err)
+		os.Exit(2)
+	}
+
+	var subs []*model.Subscription
+	for i := 1; i <= *hourly; i++ {
+		subs = append(subs, subscriber(fmt.Sprintf("hourly-%d@example.com", i), "hourly"))
+	}
+	for i := 1; i <= *daily; i++ {
+		subs = append(subs, subscriber(fmt.Sprintf("daily-%d@example.com", i), "daily"))
+	}
+
+	report, err := simulation.Simulate(context.Background(), simulation.Config{
+		Start:         from,
+		Duration:      time.Duration(*days) * 24 * time.Hour,
+		Subscriptions: subs,
+		Outages:       outages,
+		CatchUpGrace:  *grace,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulation failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tKIND\tRECIPIENT\tCITY")
+	for _, e := range report.Emails {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.At.Format(time.RFC3339), e.Kind, e.Recipient, e.City)
+	}
+	_ = w.Flush()
+
+	catchUps := 0
+	for _, r := range report.Runs {
+		if r.CatchUp {
+			catchUps++
+		}
+	}
+	fmt.Printf("\n%d runs (%d catch-up), %d emails\n", len(report.Runs), catchUps, len(report.Emails))
+	counts := report.PerRecipient()
+	recipients := make([]string, 0, len(counts))
+	for r := range counts {
+		recipients = append(recipients, r)
+	}
+	sort.Strings(recipients)
+	for _, r := range recipients {
+		fmt.Printf("  %s: %d\n", r, counts[r])
+	}
+}
+
+func subscriber(email, frequency string) *model.Subscription {
+	return &model.Subscription{
+		Email:            email,
+		City:             "Kyiv",
+		Frequency:        frequency,
+		Confirmed:        true,
+		ConfirmToken:     "confirm-" + email,
+		UnsubscribeToken: "unsub-" + email,
+	}
+}
@@ -0,0 +1,134 @@
+// Package channel holds the notify.Notifier adapters for chat services and
+// generic webhooks. Every adapter takes the base URL of the service it talks
+// to, so tests and staging can point it at a local stub.
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/adapter/channel")
+
+func newClient() *http.Client {
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+}
+
+var errNonPublicAddress = errors.New("destination is not a public address")
+
+// newPublicClient only connects to public addresses. The check runs on the
+// resolved address of every dial, redirects included, so DNS cannot be used
+// to reach internal hosts after the URL was validated.
+func newPublicClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublic(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errNonPublicAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would make the dial check see the proxy instead of the target.
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: otelhttp.NewTransport(transport),
+	}
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, where some clouds serve
+// their metadata endpoints.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublic rejects loopback, private, link-local (169.254.169.254 among
+// them), shared, multicast and unspecified addresses.
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// postJSON posts body as JSON to target, signed with secret when it is set,
+// and returns the start of the response body. Any non-2xx status is an error.
+func postJSON(ctx context.Context, client *http.Client, target string, body any, secret string) ([]byte, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(secret, timestamp, payload))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s responded %s", req.URL.Host, resp.Status)
+	}
+	return respBody, nil
+}
+
+// underBase checks that destination is an absolute URL on the same scheme and
+// host as base and below its path, so a subscriber cannot point a Slack or
+// Discord subscription at an arbitrary host.
+func underBase(base, destination string) error {
+	b, err := url.Parse(base)
+	if err != nil {
+		return err
+	}
+	d, err := url.Parse(destination)
+	if err != nil {
+		return err
+	}
+	if d.Scheme != b.Scheme || d.Host != b.Host {
+		return fmt.Errorf("destination must be under %s", base)
+	}
+	prefix := strings.TrimSuffix(b.Path, "/") + "/"
+	if !strings.HasPrefix(d.Path, prefix) || d.Path == prefix {
+		return fmt.Errorf("destination must be under %s", base)
+	}
+	return nil
+}
+
+var errEmptyDestination = errors.New("destination is empty")
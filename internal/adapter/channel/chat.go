@@ -0,0 +1,68 @@
+package channel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// IncomingWebhookNotifier posts weather updates to Slack or Discord incoming
+// webhooks. The destination is the full webhook URL, which must sit under
+// the configured base URL.
+type IncomingWebhookNotifier struct {
+	name    string
+	baseURL string
+	field   string
+	client  *http.Client
+	logger  *zap.Logger
+}
+
+var (
+	_ notify.Notifier             = (*IncomingWebhookNotifier)(nil)
+	_ notify.DestinationValidator = (*IncomingWebhookNotifier)(nil)
+)
+
+// NewSlackNotifier posts {"text": ...} to Slack incoming webhooks.
+func NewSlackNotifier(baseURL string, logger *zap.Logger) *IncomingWebhookNotifier {
+	return &IncomingWebhookNotifier{name: "Slack", baseURL: baseURL, field: "text", client: newClient(), logger: pkg.OrNop(logger)}
+}
+
+// NewDiscordNotifier posts {"content": ...} to Discord webhooks.
+func NewDiscordNotifier(baseURL string, logger *zap.Logger) *IncomingWebhookNotifier {
+	return &IncomingWebhookNotifier{name: "Discord", baseURL: baseURL, field: "content", client: newClient(), logger: pkg.OrNop(logger)}
+}
+
+func (n *IncomingWebhookNotifier) Notify(ctx context.Context, destination string, msg notify.Message) error {
+	ctx, span := tracer.Start(ctx, n.name+"Notifier.Notify", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(attribute.String("weather.city", msg.Update.City))
+
+	if err := n.ValidateDestination(destination); err != nil {
+		return tracing.Error(span, err)
+	}
+	// Webhook URLs are credentials, so they are kept out of logs and out of
+	// the error, which the dispatcher stores as the outbox last_error.
+	if _, err := postJSON(ctx, n.client, destination, map[string]string{n.field: msg.Text}, ""); err != nil {
+		err = redact(err, destination)
+		pkg.FromContext(ctx, n.logger).Error("Failed to post "+n.name+" message",
+			zap.String("city", msg.Update.City),
+			zap.Error(err),
+		)
+		return tracing.Error(span, err)
+	}
+	pkg.FromContext(ctx, n.logger).Info(n.name+" message sent", zap.String("city", msg.Update.City))
+	return nil
+}
+
+func (n *IncomingWebhookNotifier) ValidateDestination(destination string) error {
+	if destination == "" {
+		return errEmptyDestination
+	}
+	return underBase(n.baseURL, destination)
+}
@@ -0,0 +1,83 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// TelegramNotifier sends weather updates through a Telegram bot. The
+// destination is a chat ID or an @channel username.
+type TelegramNotifier struct {
+	apiURL string
+	token  string
+	client *http.Client
+	logger *zap.Logger
+}
+
+var (
+	_ notify.Notifier             = (*TelegramNotifier)(nil)
+	_ notify.DestinationValidator = (*TelegramNotifier)(nil)
+)
+
+func NewTelegramNotifier(apiURL, token string, logger *zap.Logger) *TelegramNotifier {
+	return &TelegramNotifier{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  token,
+		client: newClient(),
+		logger: pkg.OrNop(logger),
+	}
+}
+
+type telegramMessage struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+func (t *TelegramNotifier) Notify(ctx context.Context, destination string, msg notify.Message) error {
+	ctx, span := tracer.Start(ctx, "TelegramNotifier.Notify", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(attribute.String("weather.city", msg.Update.City))
+
+	target := fmt.Sprintf("%s/bot%s/sendMessage", t.apiURL, t.token)
+	if _, err := postJSON(ctx, t.client, target, telegramMessage{ChatID: destination, Text: msg.Text}, ""); err != nil {
+		// The bot token is part of the URL, so only the chat is logged.
+		pkg.FromContext(ctx, t.logger).Error("Failed to send Telegram message",
+			zap.String("chat_id", destination),
+			zap.Error(redact(err, t.token)),
+		)
+		return tracing.Error(span, redact(err, t.token))
+	}
+	pkg.FromContext(ctx, t.logger).Info("Telegram message sent", zap.String("chat_id", destination))
+	return nil
+}
+
+func (t *TelegramNotifier) ValidateDestination(destination string) error {
+	if destination == "" {
+		return errEmptyDestination
+	}
+	if strings.HasPrefix(destination, "@") && len(destination) > 1 {
+		return nil
+	}
+	if _, err := strconv.ParseInt(destination, 10, 64); err != nil {
+		return fmt.Errorf("telegram destination must be a chat ID or @channel")
+	}
+	return nil
+}
+
+// redact keeps the bot token out of transport errors, which quote the URL.
+func redact(err error, token string) error {
+	if token == "" || !strings.Contains(err.Error(), token) {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), token, "REDACTED"))
+}
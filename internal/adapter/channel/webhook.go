@@ -0,0 +1,179 @@
+package channel
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+
+	WebhookEventChallenge     = "challenge"
+	WebhookEventWeatherUpdate = "weather_update"
+)
+
+// Sign returns the signature header value for a webhook body sent at
+// timestamp: "sha256=" and the hex HMAC-SHA256 of timestamp + "." + body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookNotifier posts weather updates as JSON to a URL of the subscriber's
+// choosing. With a base URL set, destinations must sit under it; otherwise
+// any https URL on a public address is accepted, and every connection is
+// checked again after DNS resolution so a hostname cannot point the server at
+// loopback, private or link-local hosts such as cloud metadata endpoints.
+// Deliveries to subscriptions with a secret are signed; failed ones are
+// retried with backoff by the outbox dispatcher.
+type WebhookNotifier struct {
+	baseURL string
+	client  *http.Client
+	logger  *zap.Logger
+}
+
+var (
+	_ notify.Notifier             = (*WebhookNotifier)(nil)
+	_ notify.DestinationValidator = (*WebhookNotifier)(nil)
+	_ notify.DestinationVerifier  = (*WebhookNotifier)(nil)
+)
+
+func NewWebhookNotifier(baseURL string, logger *zap.Logger) *WebhookNotifier {
+	client := newClient()
+	if baseURL == "" {
+		client = newPublicClient()
+	}
+	return &WebhookNotifier{baseURL: baseURL, client: client, logger: pkg.OrNop(logger)}
+}
+
+// WebhookPayload is the JSON body of a weather update webhook.
+type WebhookPayload struct {
+	Type           string         `json:"type"`
+	City           string         `json:"city"`
+	Language       string         `json:"language"`
+	Weather        WebhookWeather `json:"weather"`
+	Subject        string         `json:"subject"`
+	Text           string         `json:"text"`
+	UnsubscribeURL string         `json:"unsubscribe_url"`
+}
+
+type WebhookWeather struct {
+	Temperature float64 `json:"temperature"`
+	Humidity    int     `json:"humidity"`
+	Description string  `json:"description"`
+}
+
+// WebhookChallenge is posted to a webhook destination when the subscription
+// is confirmed by email. The endpoint proves it accepts the subscription by
+// answering 2xx with the same challenge in a JSON body, and keeps Secret to
+// check later signatures.
+type WebhookChallenge struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Secret    string `json:"secret"`
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, destination string, msg notify.Message) error {
+	ctx, span := tracer.Start(ctx, "WebhookNotifier.Notify", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(attribute.String("weather.city", msg.Update.City))
+
+	if err := w.ValidateDestination(destination); err != nil {
+		return tracing.Error(span, err)
+	}
+	payload := WebhookPayload{
+		Type:     WebhookEventWeatherUpdate,
+		City:     msg.Update.City,
+		Language: msg.Update.Language,
+		Weather: WebhookWeather{
+			Temperature: msg.Update.Weather.Temperature,
+			Humidity:    msg.Update.Weather.Humidity,
+			Description: msg.Update.Weather.Description,
+		},
+		Subject:        msg.Subject,
+		Text:           msg.Text,
+		UnsubscribeURL: msg.UnsubscribeURL,
+	}
+	if _, err := postJSON(ctx, w.client, destination, payload, msg.Secret); err != nil {
+		pkg.FromContext(ctx, w.logger).Error("Failed to post weather webhook",
+			zap.String("city", msg.Update.City),
+			zap.Error(err),
+		)
+		return tracing.Error(span, err)
+	}
+	pkg.FromContext(ctx, w.logger).Info("Weather webhook sent", zap.String("city", msg.Update.City))
+	return nil
+}
+
+// VerifyDestination sends a signed WebhookChallenge and expects it echoed.
+func (w *WebhookNotifier) VerifyDestination(ctx context.Context, destination, secret string) error {
+	ctx, span := tracer.Start(ctx, "WebhookNotifier.VerifyDestination", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return tracing.Error(span, err)
+	}
+	challenge := hex.EncodeToString(b)
+	body, err := postJSON(ctx, w.client, destination, WebhookChallenge{
+		Type:      WebhookEventChallenge,
+		Challenge: challenge,
+		Secret:    secret,
+	}, secret)
+	if err != nil {
+		pkg.FromContext(ctx, w.logger).Warn("Webhook challenge failed", zap.Error(err))
+		return tracing.Error(span, err)
+	}
+	var answer struct {
+		Challenge string `json:"challenge"`
+	}
+	if err := json.Unmarshal(body, &answer); err != nil || !hmac.Equal([]byte(answer.Challenge), []byte(challenge)) {
+		pkg.FromContext(ctx, w.logger).Warn("Webhook did not echo the challenge")
+		return tracing.Error(span, fmt.Errorf("webhook did not echo the challenge"))
+	}
+	return nil
+}
+
+func (w *WebhookNotifier) ValidateDestination(destination string) error {
+	if destination == "" {
+		return errEmptyDestination
+	}
+	if w.baseURL != "" {
+		return underBase(w.baseURL, destination)
+	}
+	u, err := url.Parse(destination)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("webhook destination must be an https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errNonPublicAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublic(addr) {
+		return errNonPublicAddress
+	}
+	return nil
+}
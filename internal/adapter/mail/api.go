@@ -0,0 +1,101 @@
+package mail
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const apiTimeout = 30 * time.Second
+
+// APIError is a non-2xx response from an email API.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s responded %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Temporary reports throttling and server errors; the same request may be
+// accepted later.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Permanent reports a 4xx rejection of the message itself, such as an invalid
+// recipient. Authentication and not found errors mean our own setup is wrong,
+// so like 5xx replies to MAIL FROM they are retried until it is fixed.
+func (e *APIError) Permanent() bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return false
+	}
+	return e.StatusCode >= 400 && e.StatusCode < 500 && !e.Temporary()
+}
+
+// apiClient is the part the HTTP transports share: the provider name, the base
+// URL, which can point at a local stub, and an instrumented client.
+type apiClient struct {
+	provider string
+	baseURL  string
+	client   *http.Client
+}
+
+func newAPIClient(provider, baseURL string) (apiClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return apiClient{}, fmt.Errorf("invalid %s API URL %q", provider, baseURL)
+	}
+	return apiClient{
+		provider: provider,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Timeout:   apiTimeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}, nil
+}
+
+func (c *apiClient) Name() string {
+	return c.provider
+}
+
+// Addr is the host:port of the API, for readiness checks.
+func (c *apiClient) Addr() string {
+	u, _ := url.Parse(c.baseURL)
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "http" {
+		return net.JoinHostPort(u.Hostname(), "80")
+	}
+	return net.JoinHostPort(u.Hostname(), "443")
+}
+
+func (c *apiClient) Close() {
+	c.client.CloseIdleConnections()
+}
+
+// do sends req and turns any non-2xx response into an *APIError carrying the
+// start of the response body.
+func (c *apiClient) do(req *http.Request) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<12))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{Provider: c.provider, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
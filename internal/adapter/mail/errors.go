@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+const (
+	CommandMail = "MAIL"
+	CommandRcpt = "RCPT"
+	CommandData = "DATA"
+)
+
+// SMTPError is a reply the server refused a message with. Command is the SMTP
+// command the reply answered and EnhancedCode the RFC 3463 status at the start
+// of the reply text, if any.
+type SMTPError struct {
+	Command      string
+	Code         int
+	EnhancedCode string
+	Message      string
+}
+
+func (e *SMTPError) Error() string {
+	return fmt.Sprintf("smtp %s: %d %s", e.Command, e.Code, e.Message)
+}
+
+// Temporary reports a 4xx reply; the same message may be accepted later.
+func (e *SMTPError) Temporary() bool {
+	return e.Code >= 400 && e.Code < 500
+}
+
+// Permanent reports a 5xx reply to the recipient or the message itself, which
+// no retry of the same message can fix. 5xx replies to MAIL FROM usually mean
+// our own setup is wrong, so they are retried until it is fixed.
+func (e *SMTPError) Permanent() bool {
+	return e.Code >= 500 && (e.Command == CommandRcpt || e.Command == CommandData)
+}
+
+// RecipientRejected reports a permanent failure caused by the mailbox, such as
+// 550 mailbox unavailable, rather than by the message or by policy.
+func (e *SMTPError) RecipientRejected() bool {
+	if !e.Permanent() || strings.HasPrefix(e.EnhancedCode, "5.7.") {
+		return false
+	}
+	return e.Command == CommandRcpt || strings.HasPrefix(e.EnhancedCode, "5.1.") || e.EnhancedCode == "5.2.1"
+}
+
+var enhancedCode = regexp.MustCompile(`^[245]\.\d{1,3}\.\d{1,3}\b`)
+
+// classify turns a server reply to command into an *SMTPError and returns any
+// other error, such as a broken connection, unchanged.
+func classify(command string, err error) error {
+	var reply *textproto.Error
+	if !errors.As(err, &reply) {
+		return err
+	}
+	return &SMTPError{
+		Command:      command,
+		Code:         reply.Code,
+		EnhancedCode: enhancedCode.FindString(reply.Msg),
+		Message:      reply.Msg,
+	}
+}
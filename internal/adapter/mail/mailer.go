@@ -0,0 +1,175 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/adapter/mail")
+
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+	ProviderMailgun  = "mailgun"
+	ProviderSES      = "ses"
+)
+
+// Transport hands a rendered email to the SMTP relay or email API that
+// delivers it.
+type Transport interface {
+	Send(ctx context.Context, from, to string, email *templates.Email) error
+	// Name is the provider, such as ProviderSMTP.
+	Name() string
+	Close()
+}
+
+// Mailer renders the email templates and sends them through a Transport, so
+// every provider gets the same content and List-Unsubscribe headers.
+type Mailer struct {
+	From      string
+	BaseURL   string
+	transport Transport
+	logger    *zap.Logger
+}
+
+func NewMailer(transport Transport, from, baseURL string, logger *zap.Logger) *Mailer {
+	return &Mailer{
+		From:      from,
+		BaseURL:   baseURL,
+		transport: transport,
+		logger:    pkg.OrNop(logger),
+	}
+}
+
+// Close releases the connections of the transport.
+func (m *Mailer) Close() {
+	m.transport.Close()
+}
+
+func (m *Mailer) startSpan(ctx context.Context, name, emailType string) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, "Mailer."+name, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("mail.provider", m.transport.Name()),
+		attribute.String("email.type", emailType),
+	)
+	return ctx, span
+}
+
+// send renders the named email templates in lang with data and sends the
+// result. A non-empty unsubscribeToken adds the one-click List-Unsubscribe
+// headers.
+func (m *Mailer) send(ctx context.Context, to, lang, name string, data any, unsubscribeToken string) error {
+	email, err := templates.Render(lang, name, data)
+	if err != nil {
+		return err
+	}
+	if unsubscribeToken != "" {
+		email.ListUnsubscribe = m.BaseURL + "/api/unsubscribe/" + unsubscribeToken
+	}
+	return m.transport.Send(ctx, m.From, to, email)
+}
+
+func (m *Mailer) SendConfirmation(ctx context.Context, email, token, lang string) error {
+	ctx, span := m.startSpan(ctx, "SendConfirmation", metrics.EmailTypeConfirmation)
+	defer span.End()
+
+	err := m.send(ctx, email, lang, templates.Confirmation, templates.ConfirmationData{BaseURL: m.BaseURL, Token: token}, "")
+	if err != nil {
+		metrics.EmailsFailed.WithLabelValues(metrics.EmailTypeConfirmation).Inc()
+		pkg.FromContext(ctx, m.logger).Error("Failed to send confirmation email",
+			zap.String("to", email),
+			zap.Error(err),
+		)
+		return tracing.Error(span, err)
+	}
+	metrics.EmailsSent.WithLabelValues(metrics.EmailTypeConfirmation).Inc()
+	pkg.FromContext(ctx, m.logger).Info("Confirmation email sent",
+		zap.String("to", email),
+	)
+	return nil
+}
+
+func (m *Mailer) SendWeatherUpdate(ctx context.Context, email string, update model.WeatherUpdate) error {
+	ctx, span := m.startSpan(ctx, "SendWeatherUpdate", metrics.EmailTypeWeatherUpdate)
+	defer span.End()
+
+	err := m.send(ctx, email, update.Language, templates.WeatherUpdate, templates.WeatherUpdateData{
+		BaseURL:          m.BaseURL,
+		City:             update.City,
+		Weather:          update.Weather,
+		UnsubscribeToken: update.UnsubscribeToken,
+	}, update.UnsubscribeToken)
+	if err != nil {
+		metrics.EmailsFailed.WithLabelValues(metrics.EmailTypeWeatherUpdate).Inc()
+		pkg.FromContext(ctx, m.logger).Error("Failed to send weather update",
+			zap.String("to", email),
+			zap.String("city", update.City),
+			zap.Error(err),
+		)
+		return tracing.Error(span, err)
+	}
+	metrics.EmailsSent.WithLabelValues(metrics.EmailTypeWeatherUpdate).Inc()
+	pkg.FromContext(ctx, m.logger).Info("Weather update sent",
+		zap.String("to", email),
+		zap.String("city", update.City),
+	)
+	return nil
+}
+
+// SendAlert emails notable weather outside the regular schedule. reason says
+// what triggered the alert.
+func (m *Mailer) SendAlert(ctx context.Context, email string, update model.WeatherUpdate, reason string) error {
+	ctx, span := m.startSpan(ctx, "SendAlert", metrics.EmailTypeAlert)
+	defer span.End()
+
+	err := m.send(ctx, email, update.Language, templates.Alert, templates.AlertData{
+		BaseURL:          m.BaseURL,
+		City:             update.City,
+		Weather:          update.Weather,
+		Reason:           reason,
+		UnsubscribeToken: update.UnsubscribeToken,
+	}, update.UnsubscribeToken)
+	if err != nil {
+		metrics.EmailsFailed.WithLabelValues(metrics.EmailTypeAlert).Inc()
+		pkg.FromContext(ctx, m.logger).Error("Failed to send weather alert",
+			zap.String("to", email),
+			zap.String("city", update.City),
+			zap.Error(err),
+		)
+		return tracing.Error(span, err)
+	}
+	metrics.EmailsSent.WithLabelValues(metrics.EmailTypeAlert).Inc()
+	pkg.FromContext(ctx, m.logger).Info("Weather alert sent",
+		zap.String("to", email),
+		zap.String("city", update.City),
+	)
+	return nil
+}
+
+func (m *Mailer) SendGoodbye(ctx context.Context, email, city, lang string) error {
+	ctx, span := m.startSpan(ctx, "SendGoodbye", metrics.EmailTypeGoodbye)
+	defer span.End()
+
+	err := m.send(ctx, email, lang, templates.Goodbye, templates.GoodbyeData{BaseURL: m.BaseURL, City: city}, "")
+	if err != nil {
+		metrics.EmailsFailed.WithLabelValues(metrics.EmailTypeGoodbye).Inc()
+		pkg.FromContext(ctx, m.logger).Error("Failed to send goodbye email",
+			zap.String("to", email),
+			zap.Error(err),
+		)
+		return tracing.Error(span, err)
+	}
+	metrics.EmailsSent.WithLabelValues(metrics.EmailTypeGoodbye).Inc()
+	pkg.FromContext(ctx, m.logger).Info("Goodbye email sent",
+		zap.String("to", email),
+	)
+	return nil
+}
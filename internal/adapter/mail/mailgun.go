@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+)
+
+const MailgunBaseURL = "https://api.mailgun.net"
+
+// MailgunTransport posts the same MIME message SMTPTransport sends to the
+// Mailgun messages.mime endpoint of domain.
+type MailgunTransport struct {
+	apiClient
+	domain string
+	apiKey string
+}
+
+// NewMailgunTransport uses MailgunBaseURL when baseURL is empty. EU domains
+// need https://api.eu.mailgun.net.
+func NewMailgunTransport(baseURL, domain, apiKey string) (*MailgunTransport, error) {
+	if apiKey == "" || domain == "" {
+		return nil, errors.New("mailgun API key and domain must be set")
+	}
+	if baseURL == "" {
+		baseURL = MailgunBaseURL
+	}
+	client, err := newAPIClient(ProviderMailgun, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &MailgunTransport{apiClient: client, domain: domain, apiKey: apiKey}, nil
+}
+
+func (t *MailgunTransport) Send(ctx context.Context, from, to string, email *templates.Email) error {
+	msg, err := email.MIME(from, to, time.Now())
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("to", to); err != nil {
+		return err
+	}
+	part, err := w.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	target := t.baseURL + "/v3/" + url.PathEscape(t.domain) + "/messages.mime"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, &body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", t.apiKey)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return t.do(req)
+}
@@ -0,0 +1,308 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+)
+
+const (
+	// TLSAuto uses implicit TLS on port 465 and STARTTLS elsewhere when the
+	// server offers it, like smtp.SendMail.
+	TLSAuto = "auto"
+	// TLSStartTLS refuses servers that do not offer STARTTLS.
+	TLSStartTLS = "starttls"
+	// TLSImplicit dials straight into TLS (SMTPS).
+	TLSImplicit = "tls"
+	// TLSNone never encrypts. Only meant for local relays.
+	TLSNone = "none"
+)
+
+var ErrPoolClosed = errors.New("smtp pool is closed")
+
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// TLSMode is one of TLSAuto (the default), TLSStartTLS, TLSImplicit and
+	// TLSNone.
+	TLSMode string
+	// CAFile, when set, replaces the system roots with the PEM certificates
+	// in it.
+	CAFile string
+	// SkipVerify disables certificate verification. Only meant for
+	// development relays with self-signed certificates.
+	SkipVerify bool
+	// PoolSize bounds the connections open at the same time.
+	PoolSize int
+	// IdleTimeout closes connections that were not used for this long, before
+	// the relay drops them.
+	IdleTimeout time.Duration
+	// Timeout bounds dialing and every message sent on a connection.
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		TLSMode:     TLSAuto,
+		PoolSize:    2,
+		IdleTimeout: 30 * time.Second,
+		Timeout:     30 * time.Second,
+	}
+}
+
+type conn struct {
+	raw      net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// Pool keeps authenticated SMTP connections open between messages, so a burst
+// of emails pays for the handshake once per connection instead of per email.
+// Connections are RSET before reuse and replaced when that fails.
+type Pool struct {
+	cfg    Config
+	tls    *tls.Config
+	slots  chan struct{}
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// NewPool fills zero fields of cfg from DefaultConfig. It fails when the CA
+// file cannot be loaded or the TLS mode is unknown.
+func NewPool(cfg Config, logger *zap.Logger) (*Pool, error) {
+	def := DefaultConfig()
+	if cfg.TLSMode == "" {
+		cfg.TLSMode = def.TLSMode
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = def.PoolSize
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = def.IdleTimeout
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	switch cfg.TLSMode {
+	case TLSAuto, TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", cfg.TLSMode)
+	}
+	if cfg.TLSMode == TLSAuto && cfg.Port == "465" {
+		cfg.TLSMode = TLSImplicit
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.Host,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.SkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SMTP CA file: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in SMTP CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	return &Pool{
+		cfg:    cfg,
+		tls:    tlsConfig,
+		slots:  make(chan struct{}, cfg.PoolSize),
+		logger: pkg.OrNop(logger),
+	}, nil
+}
+
+func (p *Pool) Host() string {
+	return p.cfg.Host
+}
+
+// Send delivers msg to the recipients over a pooled connection. It waits for a
+// free connection until ctx is done.
+func (p *Pool) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	c, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+	if err := p.send(c, from, to, msg); err != nil {
+		var reply *SMTPError
+		if errors.As(err, &reply) {
+			// The server refused this message but the session is intact;
+			// the next send resets it.
+			p.put(c)
+		} else {
+			p.discard(c)
+		}
+		return err
+	}
+	p.put(c)
+	return nil
+}
+
+func (p *Pool) send(c *conn, from string, to []string, msg []byte) error {
+	if err := c.raw.SetDeadline(time.Now().Add(p.cfg.Timeout)); err != nil {
+		return err
+	}
+	if err := c.client.Mail(from); err != nil {
+		return classify(CommandMail, err)
+	}
+	for _, rcpt := range to {
+		if err := c.client.Rcpt(rcpt); err != nil {
+			return classify(CommandRcpt, err)
+		}
+	}
+	w, err := c.client.Data()
+	if err != nil {
+		return classify(CommandData, err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return classify(CommandData, w.Close())
+}
+
+// get returns an idle connection that still answers RSET, or dials a new one.
+func (p *Pool) get(ctx context.Context) (*conn, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		var c *conn
+		if n := len(p.idle); n > 0 {
+			c = p.idle[n-1]
+			p.idle = p.idle[:n-1]
+		}
+		p.mu.Unlock()
+
+		if c == nil {
+			return p.dial(ctx)
+		}
+		if time.Since(c.lastUsed) > p.cfg.IdleTimeout {
+			p.discard(c)
+			continue
+		}
+		if err := c.raw.SetDeadline(time.Now().Add(p.cfg.Timeout)); err == nil {
+			if err = c.client.Reset(); err == nil {
+				return c, nil
+			}
+			pkg.FromContext(ctx, p.logger).Debug("Reconnecting stale SMTP connection", zap.Error(err))
+		}
+		p.discard(c)
+	}
+}
+
+func (p *Pool) dial(ctx context.Context) (*conn, error) {
+	addr := net.JoinHostPort(p.cfg.Host, p.cfg.Port)
+	dialer := &net.Dialer{Timeout: p.cfg.Timeout}
+	var raw net.Conn
+	var err error
+	if p.cfg.TLSMode == TLSImplicit {
+		raw, err = (&tls.Dialer{NetDialer: dialer, Config: p.tls}).DialContext(ctx, "tcp", addr)
+	} else {
+		raw, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := raw.SetDeadline(time.Now().Add(p.cfg.Timeout)); err != nil {
+		raw.Close()
+		return nil, err
+	}
+	client, err := smtp.NewClient(raw, p.cfg.Host)
+	if err != nil {
+		raw.Close()
+		return nil, err
+	}
+	if err := p.handshake(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+	pkg.FromContext(ctx, p.logger).Debug("SMTP connection opened", zap.String("addr", addr))
+	return &conn{raw: raw, client: client}, nil
+}
+
+func (p *Pool) handshake(client *smtp.Client) error {
+	if err := client.Hello("localhost"); err != nil {
+		return err
+	}
+	if p.cfg.TLSMode == TLSAuto || p.cfg.TLSMode == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(p.tls); err != nil {
+				return err
+			}
+		} else if p.cfg.TLSMode == TLSStartTLS {
+			return fmt.Errorf("smtp server %s does not offer STARTTLS", p.cfg.Host)
+		}
+	}
+	if p.cfg.Username == "" {
+		return nil
+	}
+	if ok, _ := client.Extension("AUTH"); !ok {
+		return fmt.Errorf("smtp server %s does not support AUTH", p.cfg.Host)
+	}
+	return client.Auth(smtp.PlainAuth("", p.cfg.Username, p.cfg.Password, p.cfg.Host))
+}
+
+func (p *Pool) put(c *conn) {
+	c.lastUsed = time.Now()
+	p.mu.Lock()
+	closed := p.closed
+	if !closed {
+		p.idle = append(p.idle, c)
+	}
+	p.mu.Unlock()
+	if closed {
+		p.quit(c)
+	}
+}
+
+func (p *Pool) discard(c *conn) {
+	_ = c.client.Close()
+}
+
+func (p *Pool) quit(c *conn) {
+	_ = c.raw.SetDeadline(time.Now().Add(5 * time.Second))
+	_ = c.client.Quit()
+	_ = c.client.Close()
+}
+
+// Close says QUIT on the idle connections. Connections still sending are
+// closed when they are returned.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+	for _, c := range idle {
+		p.quit(c)
+	}
+}
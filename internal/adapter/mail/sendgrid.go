@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	netmail "net/mail"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+)
+
+const SendGridBaseURL = "https://api.sendgrid.com"
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+// SendGridTransport sends through the SendGrid v3 mail send API, which takes
+// the parts and headers as JSON instead of a MIME message.
+type SendGridTransport struct {
+	apiClient
+	apiKey string
+}
+
+// NewSendGridTransport uses SendGridBaseURL when baseURL is empty.
+func NewSendGridTransport(baseURL, apiKey string) (*SendGridTransport, error) {
+	if apiKey == "" {
+		return nil, errors.New("sendgrid API key is not set")
+	}
+	if baseURL == "" {
+		baseURL = SendGridBaseURL
+	}
+	client, err := newAPIClient(ProviderSendGrid, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &SendGridTransport{apiClient: client, apiKey: apiKey}, nil
+}
+
+func (t *SendGridTransport) Send(ctx context.Context, from, to string, email *templates.Email) error {
+	sender := sendGridAddress{Email: from}
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		sender = sendGridAddress{Email: addr.Address, Name: addr.Name}
+	}
+	msg := sendGridMessage{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: to}}}},
+		From:             sender,
+		Subject:          email.Subject,
+		Content: []sendGridContent{
+			{Type: "text/plain", Value: email.Text},
+			{Type: "text/html", Value: email.HTML},
+		},
+		Headers: unsubscribeHeaders(email),
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	return t.do(req)
+}
+
+// unsubscribeHeaders are the one-click headers MIME adds, for APIs that take
+// headers separately.
+func unsubscribeHeaders(email *templates.Email) map[string]string {
+	if email.ListUnsubscribe == "" {
+		return nil
+	}
+	return map[string]string{
+		"List-Unsubscribe":      "<" + email.ListUnsubscribe + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
@@ -0,0 +1,144 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+)
+
+type SESCredentials struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+type sesMessage struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data []byte `json:"Data"`
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+// SESTransport sends the same MIME message SMTPTransport sends as a raw email
+// through the SES v2 API, signing requests with AWS Signature Version 4.
+type SESTransport struct {
+	apiClient
+	creds SESCredentials
+}
+
+// NewSESTransport uses the SES endpoint of the region when baseURL is empty.
+func NewSESTransport(baseURL string, creds SESCredentials) (*SESTransport, error) {
+	if creds.Region == "" || creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, errors.New("SES region and credentials must be set")
+	}
+	if baseURL == "" {
+		baseURL = "https://email." + creds.Region + ".amazonaws.com"
+	}
+	client, err := newAPIClient(ProviderSES, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &SESTransport{apiClient: client, creds: creds}, nil
+}
+
+func (t *SESTransport) Send(ctx context.Context, from, to string, email *templates.Email) error {
+	now := time.Now()
+	raw, err := email.MIME(from, to, now)
+	if err != nil {
+		return err
+	}
+	var msg sesMessage
+	msg.FromEmailAddress = from
+	msg.Destination.ToAddresses = []string{to}
+	msg.Content.Raw.Data = raw // encoding/json base64-encodes []byte, as SES expects.
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SignV4(req, body, t.creds, "ses", now)
+	return t.do(req)
+}
+
+// SignV4 adds the X-Amz-Date and Authorization headers of AWS Signature
+// Version 4 to req, signing the host, the content type and the date.
+func SignV4(req *http.Request, body []byte, creds SESCredentials, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host, "x-amz-date": amzDate}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := day + "/" + creds.Region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	for _, part := range []string{creds.Region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -1,75 +1,40 @@
 package mail
 
 import (
-	"fmt"
-	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"context"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
 	"go.uber.org/zap"
-	"net/smtp"
 )
 
-type SMTPMailer struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
-	From     string
-	BaseURL  string
+// SMTPTransport sends emails as multipart MIME messages over a Pool.
+type SMTPTransport struct {
+	pool *Pool
 }
 
-func NewSMTPMailer(host, port, username, password, from, baseURL string) *SMTPMailer {
-	return &SMTPMailer{
-		Host:     host,
-		Port:     port,
-		Username: username,
-		Password: password,
-		From:     from,
-		BaseURL:  baseURL,
-	}
+func NewSMTPTransport(pool *Pool) *SMTPTransport {
+	return &SMTPTransport{pool: pool}
 }
 
-func (m *SMTPMailer) SendConfirmation(email, token string) error {
-	addr := fmt.Sprintf("%s:%s", m.Host, m.Port)
-	subject := "Confirm your weather subscription"
-	link := fmt.Sprintf("%s/api/confirm/%s", m.BaseURL, token)
-	body := fmt.Sprintf("To confirm your subscription, click the link: %s", link)
-
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
-		m.From, email, subject, body)
-	auth := smtp.PlainAuth("", m.Username, m.Password, m.Host)
-	err := smtp.SendMail(addr, auth, m.From, []string{email}, []byte(msg))
-	if err != nil {
-		pkg.Logger.Error("Failed to send confirmation email",
-			zap.String("to", email),
-			zap.Error(err),
-		)
-		return err
-	}
-	pkg.Logger.Info("Confirmation email sent",
-		zap.String("to", email),
-	)
-	return nil
+// NewSMTPMailer returns a Mailer that sends over pool.
+func NewSMTPMailer(pool *Pool, from, baseURL string, logger *zap.Logger) *Mailer {
+	return NewMailer(NewSMTPTransport(pool), from, baseURL, logger)
 }
 
-func (m *SMTPMailer) SendWeatherUpdate(email, city, weatherInfo string) error {
-	addr := fmt.Sprintf("%s:%s", m.Host, m.Port)
-	subject := fmt.Sprintf("Weather update for %s", city)
-	body := weatherInfo
+func (t *SMTPTransport) Name() string {
+	return ProviderSMTP
+}
 
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
-		m.From, email, subject, body)
-	auth := smtp.PlainAuth("", m.Username, m.Password, m.Host)
-	err := smtp.SendMail(addr, auth, m.From, []string{email}, []byte(msg))
+func (t *SMTPTransport) Send(ctx context.Context, from, to string, email *templates.Email) error {
+	msg, err := email.MIME(from, to, time.Now())
 	if err != nil {
-		pkg.Logger.Error("Failed to send weather update",
-			zap.String("to", email),
-			zap.String("city", city),
-			zap.Error(err),
-		)
 		return err
 	}
-	pkg.Logger.Info("Weather update sent",
-		zap.String("to", email),
-		zap.String("city", city),
-	)
-	return nil
+	return t.pool.Send(ctx, from, []string{to}, msg)
+}
+
+// Close closes the pooled SMTP connections.
+func (t *SMTPTransport) Close() {
+	t.pool.Close()
 }
@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"database/sql/driver"
+	"hash/fnv"
+
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AdvisoryLocker hands out Postgres session-level advisory locks. Each held
+// lock pins one pooled connection until it is released; if the process dies
+// the connection drops and Postgres releases the lock.
+type AdvisoryLocker struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewAdvisoryLocker(db *gorm.DB, logger *zap.Logger) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db, logger: pkg.OrNop(logger)}
+}
+
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	ctx, span := startSpan(ctx, "TryLock")
+	defer span.End()
+
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, false, spanError(span, err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, spanError(span, err)
+	}
+
+	key := lockKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		l.logger.Error("Failed to acquire advisory lock", zap.String("lock", name), zap.Error(err))
+		return nil, false, spanError(span, err)
+	}
+	if !acquired {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		defer func() { _ = conn.Close() }()
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// Discard the connection instead of returning it to the pool; ending
+			// the session frees the lock.
+			l.logger.Warn("Failed to release advisory lock", zap.String("lock", name), zap.Error(err))
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}
+	return unlock, true, nil
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -6,30 +6,42 @@ import (
 
 func ToDomain(subDB *SubscriptionDB) *model.Subscription {
 	return &model.Subscription{
-		ID:               subDB.ID,
-		Email:            subDB.Email,
-		City:             subDB.City,
-		Frequency:        subDB.Frequency,
-		Confirmed:        subDB.Confirmed,
-		ConfirmToken:     subDB.ConfirmToken,
-		UnsubscribeToken: subDB.UnsubscribeToken,
-		CreatedAt:        subDB.CreatedAt,
-		UpdatedAt:        subDB.UpdatedAt,
-		LastSentAt:       subDB.LastSentAt,
+		ID:                  subDB.ID,
+		Email:               subDB.Email,
+		City:                subDB.City,
+		Frequency:           subDB.Frequency,
+		Confirmed:           subDB.Confirmed,
+		ConfirmToken:        subDB.ConfirmToken,
+		UnsubscribeToken:    subDB.UnsubscribeToken,
+		Language:            subDB.Language,
+		Channel:             subDB.Channel,
+		Destination:         subDB.Destination,
+		WebhookSecret:       subDB.WebhookSecret,
+		UndeliverableAt:     subDB.UndeliverableAt,
+		UndeliverableReason: subDB.UndeliverableReason,
+		CreatedAt:           subDB.CreatedAt,
+		UpdatedAt:           subDB.UpdatedAt,
+		LastSentAt:          subDB.LastSentAt,
 	}
 }
 
 func ToDB(sub *model.Subscription) *SubscriptionDB {
 	return &SubscriptionDB{
-		ID:               sub.ID,
-		Email:            sub.Email,
-		City:             sub.City,
-		Frequency:        sub.Frequency,
-		Confirmed:        sub.Confirmed,
-		ConfirmToken:     sub.ConfirmToken,
-		UnsubscribeToken: sub.UnsubscribeToken,
-		CreatedAt:        sub.CreatedAt,
-		UpdatedAt:        sub.UpdatedAt,
-		LastSentAt:       sub.LastSentAt,
+		ID:                  sub.ID,
+		Email:               sub.Email,
+		City:                sub.City,
+		Frequency:           sub.Frequency,
+		Confirmed:           sub.Confirmed,
+		ConfirmToken:        sub.ConfirmToken,
+		UnsubscribeToken:    sub.UnsubscribeToken,
+		Language:            sub.Language,
+		Channel:             sub.Channel,
+		Destination:         sub.Destination,
+		WebhookSecret:       sub.WebhookSecret,
+		UndeliverableAt:     sub.UndeliverableAt,
+		UndeliverableReason: sub.UndeliverableReason,
+		CreatedAt:           sub.CreatedAt,
+		UpdatedAt:           sub.UpdatedAt,
+		LastSentAt:          sub.LastSentAt,
 	}
 }
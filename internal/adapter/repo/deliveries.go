@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PostgresDeliveryRepo struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPostgresDeliveryRepo(db *gorm.DB, logger *zap.Logger) *PostgresDeliveryRepo {
+	return &PostgresDeliveryRepo{db: db, logger: pkg.OrNop(logger)}
+}
+
+func (r *PostgresDeliveryRepo) log(ctx context.Context) *zap.Logger {
+	return pkg.FromContext(ctx, r.logger)
+}
+
+func startDeliverySpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return startTableSpan(ctx, "PostgresDeliveryRepo", "deliveries", operation)
+}
+
+// Reserve creates the pending ledger entry for (subscriptionID, period), or
+// flips a failed entry back to pending for another attempt. It reports false
+// when the period is already pending or sent, in which case the caller must
+// not send. The insert and the conditional update are one statement, so two
+// workers racing for the same period cannot both win.
+func (r *PostgresDeliveryRepo) Reserve(ctx context.Context, subscriptionID int64, period time.Time) (*model.Delivery, bool, error) {
+	ctx, span := startDeliverySpan(ctx, "Reserve")
+	defer span.End()
+
+	var rows []DeliveryDB
+	err := r.db.WithContext(ctx).Raw(`
+INSERT INTO deliveries (subscription_id, period, status, attempts, last_error, created_at, updated_at)
+VALUES (?, ?, ?, 1, '', now(), now())
+ON CONFLICT (subscription_id, period) DO UPDATE
+	SET status = EXCLUDED.status, attempts = deliveries.attempts + 1, updated_at = now()
+	WHERE deliveries.status = ?
+RETURNING *`,
+		subscriptionID, period, model.DeliveryPending, model.DeliveryFailed,
+	).Scan(&rows).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to reserve delivery",
+			zap.Int64("subscription_id", subscriptionID),
+			zap.Time("period", period),
+			zap.Error(err),
+		)
+		return nil, false, spanError(span, err)
+	}
+	if len(rows) == 0 {
+		return nil, false, nil
+	}
+	return DeliveryToDomain(&rows[0]), true, nil
+}
+
+func (r *PostgresDeliveryRepo) MarkSent(ctx context.Context, id int64, at time.Time) error {
+	ctx, span := startDeliverySpan(ctx, "MarkSent")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Model(&DeliveryDB{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"status": model.DeliverySent, "sent_at": at, "last_error": ""}).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to mark delivery sent", zap.Int64("delivery_id", id), zap.Error(err))
+		return spanError(span, err)
+	}
+	return nil
+}
+
+func (r *PostgresDeliveryRepo) MarkFailed(ctx context.Context, id int64, reason string) error {
+	ctx, span := startDeliverySpan(ctx, "MarkFailed")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Model(&DeliveryDB{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"status": model.DeliveryFailed, "last_error": reason}).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to mark delivery failed", zap.Int64("delivery_id", id), zap.Error(err))
+		return spanError(span, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+)
+
+type DeliveryDB struct {
+	ID             int64      `gorm:"primaryKey"`
+	SubscriptionID int64      `gorm:"not null;uniqueIndex:uq_deliveries_subscription_period"`
+	Period         time.Time  `gorm:"not null;uniqueIndex:uq_deliveries_subscription_period"`
+	Status         string     `gorm:"size:16;not null"`
+	Attempts       int        `gorm:"not null;default:1"`
+	LastError      string     `gorm:"type:text;not null;default:''"`
+	SentAt         *time.Time `gorm:"column:sent_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (DeliveryDB) TableName() string {
+	return "deliveries"
+}
+
+func DeliveryToDomain(d *DeliveryDB) *model.Delivery {
+	return &model.Delivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		Period:         d.Period,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError,
+		SentAt:         d.SentAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
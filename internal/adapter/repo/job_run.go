@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+)
+
+type JobRunDB struct {
+	ID         int64      `gorm:"primaryKey"`
+	Name       string     `gorm:"size:64;not null"`
+	WorkerID   string     `gorm:"size:255;not null"`
+	Status     string     `gorm:"size:16;not null"`
+	StartedAt  time.Time  `gorm:"not null"`
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+	Total      int        `gorm:"not null"`
+	Sent       int        `gorm:"not null"`
+	Skipped    int        `gorm:"not null"`
+	Failed     int        `gorm:"not null"`
+	Error      string     `gorm:"type:text;not null"`
+}
+
+func (JobRunDB) TableName() string {
+	return "job_runs"
+}
+
+type JobDeliveryDB struct {
+	ID             int64     `gorm:"primaryKey"`
+	RunID          int64     `gorm:"not null;index"`
+	SubscriptionID int64     `gorm:"not null"`
+	City           string    `gorm:"size:255;not null"`
+	Status         string    `gorm:"size:16;not null"`
+	Reason         string    `gorm:"size:64;not null"`
+	Error          string    `gorm:"type:text;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (JobDeliveryDB) TableName() string {
+	return "job_deliveries"
+}
+
+func JobRunToDomain(run *JobRunDB) *model.JobRun {
+	return &model.JobRun{
+		ID:         run.ID,
+		Name:       run.Name,
+		WorkerID:   run.WorkerID,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Total:      run.Total,
+		Sent:       run.Sent,
+		Skipped:    run.Skipped,
+		Failed:     run.Failed,
+		Error:      run.Error,
+	}
+}
+
+func JobRunToDB(run *model.JobRun) *JobRunDB {
+	return &JobRunDB{
+		ID:         run.ID,
+		Name:       run.Name,
+		WorkerID:   run.WorkerID,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Total:      run.Total,
+		Sent:       run.Sent,
+		Skipped:    run.Skipped,
+		Failed:     run.Failed,
+		Error:      run.Error,
+	}
+}
+
+func JobDeliveryToDomain(d *JobDeliveryDB) *model.JobDelivery {
+	return &model.JobDelivery{
+		ID:             d.ID,
+		RunID:          d.RunID,
+		SubscriptionID: d.SubscriptionID,
+		City:           d.City,
+		Status:         d.Status,
+		Reason:         d.Reason,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+func JobDeliveryToDB(d *model.JobDelivery) *JobDeliveryDB {
+	return &JobDeliveryDB{
+		ID:             d.ID,
+		RunID:          d.RunID,
+		SubscriptionID: d.SubscriptionID,
+		City:           d.City,
+		Status:         d.Status,
+		Reason:         d.Reason,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+	}
+}
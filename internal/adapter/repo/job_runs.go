@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PostgresJobRunRepo struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPostgresJobRunRepo(db *gorm.DB, logger *zap.Logger) *PostgresJobRunRepo {
+	return &PostgresJobRunRepo{db: db, logger: pkg.OrNop(logger)}
+}
+
+func (r *PostgresJobRunRepo) log(ctx context.Context) *zap.Logger {
+	return pkg.FromContext(ctx, r.logger)
+}
+
+func startJobRunSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return startTableSpan(ctx, "PostgresJobRunRepo", "job_runs", operation)
+}
+
+func (r *PostgresJobRunRepo) StartRun(ctx context.Context, run *model.JobRun) error {
+	ctx, span := startJobRunSpan(ctx, "StartRun")
+	defer span.End()
+
+	dbRun := JobRunToDB(run)
+	if err := r.db.WithContext(ctx).Create(dbRun).Error; err != nil {
+		r.log(ctx).Error("Failed to record job run start", zap.String("job", run.Name), zap.Error(err))
+		return spanError(span, err)
+	}
+	run.ID = dbRun.ID
+	return nil
+}
+
+func (r *PostgresJobRunRepo) FinishRun(ctx context.Context, run *model.JobRun) error {
+	ctx, span := startJobRunSpan(ctx, "FinishRun")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Save(JobRunToDB(run)).Error; err != nil {
+		r.log(ctx).Error("Failed to record job run finish", zap.Int64("run_id", run.ID), zap.Error(err))
+		return spanError(span, err)
+	}
+	return nil
+}
+
+func (r *PostgresJobRunRepo) AddDeliveries(ctx context.Context, deliveries []*model.JobDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	ctx, span := startTableSpan(ctx, "PostgresJobRunRepo", "job_deliveries", "AddDeliveries")
+	defer span.End()
+	span.SetAttributes(attribute.Int("db.rows", len(deliveries)))
+
+	dbDeliveries := make([]*JobDeliveryDB, 0, len(deliveries))
+	for _, d := range deliveries {
+		dbDeliveries = append(dbDeliveries, JobDeliveryToDB(d))
+	}
+	if err := r.db.WithContext(ctx).CreateInBatches(dbDeliveries, 500).Error; err != nil {
+		r.log(ctx).Error("Failed to record job deliveries", zap.Int("count", len(deliveries)), zap.Error(err))
+		return spanError(span, err)
+	}
+	return nil
+}
+
+// ListRuns returns runs newest first. A non-zero beforeID continues from the
+// last ID of the previous page.
+func (r *PostgresJobRunRepo) ListRuns(ctx context.Context, beforeID int64, limit int) ([]*model.JobRun, error) {
+	ctx, span := startJobRunSpan(ctx, "ListRuns")
+	defer span.End()
+
+	query := r.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	var dbRuns []JobRunDB
+	if err := query.Find(&dbRuns).Error; err != nil {
+		r.log(ctx).Error("Failed to list job runs", zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	runs := make([]*model.JobRun, 0, len(dbRuns))
+	for i := range dbRuns {
+		runs = append(runs, JobRunToDomain(&dbRuns[i]))
+	}
+	return runs, nil
+}
+
+func (r *PostgresJobRunRepo) GetRun(ctx context.Context, id int64) (*model.JobRun, error) {
+	ctx, span := startJobRunSpan(ctx, "GetRun")
+	defer span.End()
+
+	var dbRun JobRunDB
+	if err := r.db.WithContext(ctx).First(&dbRun, id).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.log(ctx).Error("Failed to get job run", zap.Int64("run_id", id), zap.Error(err))
+		}
+		return nil, spanError(span, err)
+	}
+	return JobRunToDomain(&dbRun), nil
+}
+
+func (r *PostgresJobRunRepo) ListDeliveries(ctx context.Context, runID, afterID int64, limit int) ([]*model.JobDelivery, error) {
+	ctx, span := startTableSpan(ctx, "PostgresJobRunRepo", "job_deliveries", "ListDeliveries")
+	defer span.End()
+
+	var dbDeliveries []JobDeliveryDB
+	err := r.db.WithContext(ctx).
+		Where("run_id = ? AND id > ?", runID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&dbDeliveries).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to list job deliveries", zap.Int64("run_id", runID), zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	deliveries := make([]*model.JobDelivery, 0, len(dbDeliveries))
+	for i := range dbDeliveries {
+		deliveries = append(deliveries, JobDeliveryToDomain(&dbDeliveries[i]))
+	}
+	return deliveries, nil
+}
+
+// RunsSince returns the runs of the named job that started at or after since,
+// oldest first.
+func (r *PostgresJobRunRepo) RunsSince(ctx context.Context, name string, since time.Time) ([]*model.JobRun, error) {
+	ctx, span := startJobRunSpan(ctx, "RunsSince")
+	defer span.End()
+
+	var dbRuns []JobRunDB
+	err := r.db.WithContext(ctx).
+		Where("name = ? AND started_at >= ?", name, since).
+		Order("started_at, id").
+		Find(&dbRuns).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to list recent job runs", zap.String("job", name), zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	runs := make([]*model.JobRun, 0, len(dbRuns))
+	for i := range dbRuns {
+		runs = append(runs, JobRunToDomain(&dbRuns[i]))
+	}
+	return runs, nil
+}
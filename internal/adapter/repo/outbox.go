@@ -0,0 +1,170 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PostgresOutboxRepo struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPostgresOutboxRepo(db *gorm.DB, logger *zap.Logger) *PostgresOutboxRepo {
+	return &PostgresOutboxRepo{db: db, logger: pkg.OrNop(logger)}
+}
+
+var _ outbox.Store = (*PostgresOutboxRepo)(nil)
+
+func (r *PostgresOutboxRepo) log(ctx context.Context) *zap.Logger {
+	return pkg.FromContext(ctx, r.logger)
+}
+
+func startOutboxSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return startTableSpan(ctx, "PostgresOutboxRepo", "outbox", operation)
+}
+
+// ClaimDue pushes next_attempt_at of the due messages out by lease and returns
+// them. Rows another dispatcher is claiming are skipped, and a dispatcher that
+// dies mid-batch leaves its messages to be picked up once the lease expires.
+func (r *PostgresOutboxRepo) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	ctx, span := startOutboxSpan(ctx, "ClaimDue")
+	defer span.End()
+
+	var dbMsgs []OutboxMessageDB
+	err := r.db.WithContext(ctx).Raw(`
+UPDATE outbox SET next_attempt_at = ?, updated_at = now()
+WHERE id IN (
+	SELECT id FROM outbox
+	WHERE status = ? AND next_attempt_at <= ?
+	ORDER BY next_attempt_at, id
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`,
+		now.Add(lease), model.OutboxPending, now, limit,
+	).Scan(&dbMsgs).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to claim outbox messages", zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	msgs := make([]*model.OutboxMessage, 0, len(dbMsgs))
+	for i := range dbMsgs {
+		msgs = append(msgs, OutboxMessageToDomain(&dbMsgs[i]))
+	}
+	span.SetAttributes(attribute.Int("db.rows", len(msgs)))
+	return msgs, nil
+}
+
+func (r *PostgresOutboxRepo) MarkSent(ctx context.Context, id int64, attempts int, at time.Time) error {
+	return r.update(ctx, "MarkSent", id, map[string]any{
+		"status":     model.OutboxSent,
+		"attempts":   attempts,
+		"sent_at":    at,
+		"last_error": "",
+	})
+}
+
+func (r *PostgresOutboxRepo) MarkRetry(ctx context.Context, id int64, attempts int, next time.Time, lastErr string) error {
+	return r.update(ctx, "MarkRetry", id, map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": next,
+		"last_error":      lastErr,
+	})
+}
+
+func (r *PostgresOutboxRepo) MarkDead(ctx context.Context, id int64, attempts int, lastErr string) error {
+	return r.update(ctx, "MarkDead", id, map[string]any{
+		"status":     model.OutboxDead,
+		"attempts":   attempts,
+		"last_error": lastErr,
+	})
+}
+
+func (r *PostgresOutboxRepo) update(ctx context.Context, operation string, id int64, fields map[string]any) error {
+	ctx, span := startOutboxSpan(ctx, operation)
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Model(&OutboxMessageDB{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		r.log(ctx).Error("Failed to update outbox message",
+			zap.String("operation", operation),
+			zap.Int64("outbox_id", id),
+			zap.Error(err),
+		)
+		return spanError(span, err)
+	}
+	return nil
+}
+
+// ListMessages returns messages newest first, optionally filtered by status.
+// A non-zero beforeID continues from the last ID of the previous page.
+func (r *PostgresOutboxRepo) ListMessages(ctx context.Context, status string, beforeID int64, limit int) ([]*model.OutboxMessage, error) {
+	ctx, span := startOutboxSpan(ctx, "ListMessages")
+	defer span.End()
+
+	query := r.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	var dbMsgs []OutboxMessageDB
+	if err := query.Find(&dbMsgs).Error; err != nil {
+		r.log(ctx).Error("Failed to list outbox messages", zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	msgs := make([]*model.OutboxMessage, 0, len(dbMsgs))
+	for i := range dbMsgs {
+		msgs = append(msgs, OutboxMessageToDomain(&dbMsgs[i]))
+	}
+	return msgs, nil
+}
+
+func (r *PostgresOutboxRepo) GetMessage(ctx context.Context, id int64) (*model.OutboxMessage, error) {
+	ctx, span := startOutboxSpan(ctx, "GetMessage")
+	defer span.End()
+
+	var dbMsg OutboxMessageDB
+	if err := r.db.WithContext(ctx).First(&dbMsg, id).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.log(ctx).Error("Failed to get outbox message", zap.Int64("outbox_id", id), zap.Error(err))
+		}
+		return nil, spanError(span, err)
+	}
+	return OutboxMessageToDomain(&dbMsg), nil
+}
+
+// Replay moves a dead message back to pending with a fresh attempt budget.
+func (r *PostgresOutboxRepo) Replay(ctx context.Context, id int64) (*model.OutboxMessage, error) {
+	ctx, span := startOutboxSpan(ctx, "Replay")
+	defer span.End()
+
+	var dbMsgs []OutboxMessageDB
+	err := r.db.WithContext(ctx).Raw(`
+UPDATE outbox SET status = ?, attempts = 0, next_attempt_at = now(), updated_at = now()
+WHERE id = ? AND status = ?
+RETURNING *`,
+		model.OutboxPending, id, model.OutboxDead,
+	).Scan(&dbMsgs).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to replay outbox message", zap.Int64("outbox_id", id), zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	if len(dbMsgs) == 0 {
+		if _, err := r.GetMessage(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, outbox.ErrNotReplayable
+	}
+	r.log(ctx).Info("Outbox message replayed", zap.Int64("outbox_id", id))
+	return OutboxMessageToDomain(&dbMsgs[0]), nil
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+)
+
+type OutboxMessageDB struct {
+	ID            int64      `gorm:"primaryKey"`
+	Kind          string     `gorm:"size:32;not null"`
+	Recipient     string     `gorm:"size:255;not null"`
+	Payload       string     `gorm:"type:text;not null"`
+	DeliveryID    *int64     `gorm:"column:delivery_id"`
+	Status        string     `gorm:"size:16;not null"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"not null"`
+	LastError     string     `gorm:"type:text;not null;default:''"`
+	SentAt        *time.Time `gorm:"column:sent_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (OutboxMessageDB) TableName() string {
+	return "outbox"
+}
+
+func OutboxMessageToDomain(m *OutboxMessageDB) *model.OutboxMessage {
+	var payload model.OutboxPayload
+	_ = json.Unmarshal([]byte(m.Payload), &payload)
+	return &model.OutboxMessage{
+		ID:            m.ID,
+		Kind:          m.Kind,
+		Recipient:     m.Recipient,
+		Payload:       payload,
+		DeliveryID:    m.DeliveryID,
+		Status:        m.Status,
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		LastError:     m.LastError,
+		SentAt:        m.SentAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+func OutboxMessageToDB(m *model.OutboxMessage) (*OutboxMessageDB, error) {
+	payload, err := json.Marshal(m.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxMessageDB{
+		ID:            m.ID,
+		Kind:          m.Kind,
+		Recipient:     m.Recipient,
+		Payload:       string(payload),
+		DeliveryID:    m.DeliveryID,
+		Status:        m.Status,
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		LastError:     m.LastError,
+		SentAt:        m.SentAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}, nil
+}
@@ -1,113 +1,391 @@
 package repo
 
 import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/adapter/repo")
+
 type PostgresRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPostgresRepo(db *gorm.DB, logger *zap.Logger) *PostgresRepo {
+	return &PostgresRepo{db: db, logger: pkg.OrNop(logger)}
+}
+
+func (r *PostgresRepo) log(ctx context.Context) *zap.Logger {
+	return pkg.FromContext(ctx, r.logger)
+}
+
+var (
+	_ service.SubscriptionRepository = (*PostgresRepo)(nil)
+	_ metrics.SubscriptionCounter    = (*PostgresRepo)(nil)
+)
+
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return startTableSpan(ctx, "PostgresRepo", "subscriptions", operation)
+}
+
+func startTableSpan(ctx context.Context, repo, table, operation string) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, repo+"."+operation, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.sql.table", table),
+		attribute.String("db.operation", operation),
+	)
+	return ctx, span
 }
 
-func NewPostgresRepo(db *gorm.DB) *PostgresRepo {
-	return &PostgresRepo{db: db}
+// spanError records err on the span unless it is a plain "not found", which
+// callers treat as a regular outcome.
+func spanError(span trace.Span, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return tracing.Error(span, err)
 }
 
-var _ service.SubscriptionRepository = (*PostgresRepo)(nil)
+func (r *PostgresRepo) Create(ctx context.Context, sub *model.Subscription) error {
+	ctx, span := startSpan(ctx, "Create")
+	defer span.End()
 
-func (r *PostgresRepo) Create(sub *model.Subscription) error {
 	dbSub := ToDB(sub)
-	err := r.db.Create(dbSub).Error
+	err := r.db.WithContext(ctx).Create(dbSub).Error
 	if err != nil {
-		pkg.Logger.Error("Failed to create subscription",
+		r.log(ctx).Error("Failed to create subscription",
 			zap.String("email", sub.Email),
 			zap.Error(err),
 		)
-		return err
+		return spanError(span, err)
 	}
-	pkg.Logger.Info("Subscription created",
+	r.log(ctx).Info("Subscription created",
 		zap.String("email", sub.Email),
 	)
 	return nil
 }
 
-func (r *PostgresRepo) FindByEmail(email string) (*model.Subscription, error) {
+// CreateWithOutbox inserts the subscription and the email that announces it in
+// one transaction, so a subscription never exists without its confirmation
+// email queued.
+func (r *PostgresRepo) CreateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error {
+	ctx, span := startSpan(ctx, "CreateWithOutbox")
+	defer span.End()
+
+	dbSub := ToDB(sub)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(dbSub).Error; err != nil {
+			return err
+		}
+		return insertOutbox(tx, msg)
+	})
+	if err != nil {
+		r.log(ctx).Error("Failed to create subscription with outbox message",
+			zap.String("email", sub.Email),
+			zap.Error(err),
+		)
+		return spanError(span, err)
+	}
+	sub.ID = dbSub.ID
+	r.log(ctx).Info("Subscription created", zap.String("email", sub.Email), zap.Int64("outbox_id", msg.ID))
+	return nil
+}
+
+// updatableColumns are the columns Update writes. The delivery state
+// (last_sent_at, the claim and undeliverable_*) is written by the mail job and
+// the outbox dispatcher concurrently, so a subscription loaded earlier must
+// not overwrite it.
+var updatableColumns = []string{
+	"email", "city", "frequency", "confirmed", "confirm_token", "unsubscribe_token",
+	"language", "channel", "destination", "webhook_secret",
+}
+
+// UpdateWithOutbox records that sub was sent at sub.LastSentAt, releases its
+// claim and queues msg in one transaction. Other columns are left alone.
+func (r *PostgresRepo) UpdateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error {
+	ctx, span := startSpan(ctx, "UpdateWithOutbox")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&SubscriptionDB{ID: sub.ID}).Updates(map[string]any{
+			"last_sent_at":  sub.LastSentAt,
+			"claimed_by":    nil,
+			"claimed_until": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return insertOutbox(tx, msg)
+	})
+	if err != nil {
+		r.log(ctx).Error("Failed to update subscription with outbox message",
+			zap.Int64("id", sub.ID),
+			zap.Error(err),
+		)
+		return spanError(span, err)
+	}
+	r.log(ctx).Debug("Subscription updated", zap.Int64("id", sub.ID), zap.Int64("outbox_id", msg.ID))
+	return nil
+}
+
+func insertOutbox(tx *gorm.DB, msg *model.OutboxMessage) error {
+	if msg.Status == "" {
+		msg.Status = model.OutboxPending
+	}
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = time.Now()
+	}
+	dbMsg, err := OutboxMessageToDB(msg)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(dbMsg).Error; err != nil {
+		return err
+	}
+	msg.ID = dbMsg.ID
+	return nil
+}
+
+func (r *PostgresRepo) FindByEmail(ctx context.Context, email string) (*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "FindByEmail")
+	defer span.End()
+
 	var dbSub SubscriptionDB
-	err := r.db.Where("email = ?", email).First(&dbSub).Error
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&dbSub).Error
 	if err == gorm.ErrRecordNotFound {
-		pkg.Logger.Warn("Subscription not found by email", zap.String("email", email))
+		r.log(ctx).Warn("Subscription not found by email", zap.String("email", email))
 		return nil, err
 	}
 	if err != nil {
-		pkg.Logger.Error("Failed to find subscription by email",
+		r.log(ctx).Error("Failed to find subscription by email",
 			zap.String("email", email),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, spanError(span, err)
 	}
-	pkg.Logger.Info("Subscription found by email", zap.String("email", email))
+	r.log(ctx).Info("Subscription found by email", zap.String("email", email))
 	return ToDomain(&dbSub), nil
 }
 
-func (r *PostgresRepo) GetByToken(token string) (*model.Subscription, error) {
+func (r *PostgresRepo) GetByToken(ctx context.Context, token string) (*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "GetByToken")
+	defer span.End()
+
 	var dbSub SubscriptionDB
-	result := r.db.Where("confirm_token = ?", token).First(&dbSub)
+	result := r.db.WithContext(ctx).Where("confirm_token = ?", token).First(&dbSub)
 	if result.Error == gorm.ErrRecordNotFound {
-		pkg.Logger.Warn("Subscription not found by token", zap.String("token", token))
+		r.log(ctx).Warn("Subscription not found by token", zap.String("token", token))
 		return nil, result.Error
 	}
 	if result.Error != nil {
-		pkg.Logger.Error("Failed to get subscription by token", zap.String("token", token), zap.Error(result.Error))
-		return nil, result.Error
+		r.log(ctx).Error("Failed to get subscription by token", zap.String("token", token), zap.Error(result.Error))
+		return nil, spanError(span, result.Error)
 	}
-	pkg.Logger.Info("Subscription found by token", zap.String("token", token))
+	r.log(ctx).Info("Subscription found by token", zap.String("token", token))
 	return ToDomain(&dbSub), nil
 }
 
-func (r *PostgresRepo) Update(sub *model.Subscription) error {
-	err := r.db.Save(ToDB(sub)).Error
+func (r *PostgresRepo) Update(ctx context.Context, sub *model.Subscription) error {
+	ctx, span := startSpan(ctx, "Update")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Model(&SubscriptionDB{ID: sub.ID}).Select(updatableColumns).Updates(ToDB(sub)).Error
 	if err != nil {
-		pkg.Logger.Error("Failed to update subscription",
+		r.log(ctx).Error("Failed to update subscription",
 			zap.Int64("id", sub.ID),
 			zap.Error(err),
 		)
-		return err
+		return spanError(span, err)
 	}
-	pkg.Logger.Info("Subscription updated", zap.Int64("id", sub.ID))
+	r.log(ctx).Info("Subscription updated", zap.Int64("id", sub.ID))
 	return nil
 }
 
-func (r *PostgresRepo) GetAllConfirmed() ([]*model.Subscription, error) {
+func (r *PostgresRepo) GetAllConfirmed(ctx context.Context) ([]*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "GetAllConfirmed")
+	defer span.End()
+
 	var dbSubs []SubscriptionDB
-	err := r.db.Where("confirmed = ?", true).Find(&dbSubs).Error
+	err := r.db.WithContext(ctx).Where("confirmed = ?", true).Find(&dbSubs).Error
 	if err != nil {
-		pkg.Logger.Error("Failed to get all confirmed subscriptions", zap.Error(err))
-		return nil, err
+		r.log(ctx).Error("Failed to get all confirmed subscriptions", zap.Error(err))
+		return nil, spanError(span, err)
 	}
 	var subs []*model.Subscription
 	for _, dbSub := range dbSubs {
 		subs = append(subs, ToDomain(&dbSub))
 	}
-	pkg.Logger.Info("All confirmed subscriptions fetched", zap.Int("count", len(subs)))
+	span.SetAttributes(attribute.Int("db.rows", len(subs)))
+	r.log(ctx).Info("All confirmed subscriptions fetched", zap.Int("count", len(subs)))
+	return subs, nil
+}
+
+const dueCondition = "(frequency = 'hourly' AND (last_sent_at IS NULL OR last_sent_at < ?)) OR " +
+	"(frequency = 'daily' AND (last_sent_at IS NULL OR last_sent_at < ?))"
+
+// notSuppressed leaves out subscriptions whose address is on the suppression
+// list, which stores emails normalized.
+const notSuppressed = "NOT EXISTS (SELECT 1 FROM suppressions WHERE suppressions.email = lower(trim(subscriptions.email)))"
+
+func (r *PostgresRepo) ListDue(ctx context.Context, q service.DueQuery) ([]*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "ListDue")
+	defer span.End()
+
+	var dbSubs []SubscriptionDB
+	query := r.db.WithContext(ctx).
+		Where("confirmed = ? AND id > ? AND undeliverable_at IS NULL", true, q.AfterID).
+		Where(dueCondition, q.HourlySentBefore, q.DailySentBefore).
+		Where(notSuppressed)
+	if q.City != "" {
+		query = query.Where("lower(trim(city)) = ?", scopeCity(q.City))
+	}
+	if q.SubscriptionID != 0 {
+		query = query.Where("id = ?", q.SubscriptionID)
+	}
+	err := query.
+		Order("id").
+		Limit(q.Limit).
+		Find(&dbSubs).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to list due subscriptions", zap.Int64("after_id", q.AfterID), zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	subs := make([]*model.Subscription, 0, len(dbSubs))
+	for i := range dbSubs {
+		subs = append(subs, ToDomain(&dbSubs[i]))
+	}
+	span.SetAttributes(attribute.Int("db.rows", len(subs)))
+	r.log(ctx).Debug("Due subscriptions page fetched", zap.Int64("after_id", q.AfterID), zap.Int("count", len(subs)))
 	return subs, nil
 }
 
-func (r *PostgresRepo) UnsubscribeByToken(token string) error {
-	result := r.db.Where("unsubscribe_token = ?", token).Delete(&SubscriptionDB{})
+// ClaimDue marks the next page of due subscriptions as claimed by q.Owner until
+// q.Until. Rows that another worker is claiming at the same moment are skipped
+// rather than waited on, and rows with a live claim are not returned at all, so
+// concurrent workers never get the same subscription.
+func (r *PostgresRepo) ClaimDue(ctx context.Context, q service.ClaimQuery) ([]*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "ClaimDue")
+	defer span.End()
+
+	var dbSubs []SubscriptionDB
+	err := r.db.WithContext(ctx).Raw(`
+UPDATE subscriptions SET claimed_by = ?, claimed_until = ?
+WHERE id IN (
+	SELECT id FROM subscriptions
+	WHERE confirmed AND id > ? AND undeliverable_at IS NULL
+		AND (claimed_until IS NULL OR claimed_until < ?)
+		AND (`+dueCondition+`)
+		AND `+notSuppressed+`
+		AND (? = '' OR lower(trim(city)) = ?)
+		AND (? = 0 OR id = ?)
+	ORDER BY id
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`,
+		q.Owner, q.Until,
+		q.AfterID, q.Now,
+		q.HourlySentBefore, q.DailySentBefore,
+		scopeCity(q.City), scopeCity(q.City),
+		q.SubscriptionID, q.SubscriptionID,
+		q.Limit,
+	).Scan(&dbSubs).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to claim due subscriptions", zap.Int64("after_id", q.AfterID), zap.Error(err))
+		return nil, spanError(span, err)
+	}
+	sort.Slice(dbSubs, func(i, j int) bool { return dbSubs[i].ID < dbSubs[j].ID })
+
+	subs := make([]*model.Subscription, 0, len(dbSubs))
+	for i := range dbSubs {
+		subs = append(subs, ToDomain(&dbSubs[i]))
+	}
+	span.SetAttributes(attribute.Int("db.rows", len(subs)))
+	r.log(ctx).Debug("Due subscriptions claimed",
+		zap.String("owner", q.Owner),
+		zap.Int64("after_id", q.AfterID),
+		zap.Int("count", len(subs)),
+	)
+	return subs, nil
+}
+
+// MarkUndeliverable flags the subscription of email after the mail server
+// permanently rejected it, so ListDue and ClaimDue skip it.
+func (r *PostgresRepo) MarkUndeliverable(ctx context.Context, email, reason string, at time.Time) error {
+	ctx, span := startSpan(ctx, "MarkUndeliverable")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Model(&SubscriptionDB{}).
+		Where("email = ?", email).
+		Updates(map[string]any{"undeliverable_at": at, "undeliverable_reason": reason}).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to mark subscription undeliverable", zap.String("email", email), zap.Error(err))
+		return spanError(span, err)
+	}
+	r.log(ctx).Info("Subscription marked undeliverable", zap.String("email", email), zap.String("reason", reason))
+	return nil
+}
+
+func scopeCity(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+func (r *PostgresRepo) UnsubscribeByToken(ctx context.Context, token string) error {
+	ctx, span := startSpan(ctx, "UnsubscribeByToken")
+	defer span.End()
+
+	result := r.db.WithContext(ctx).Where("unsubscribe_token = ?", token).Delete(&SubscriptionDB{})
 	if result.Error != nil {
-		pkg.Logger.Error("Failed to unsubscribe by token",
+		r.log(ctx).Error("Failed to unsubscribe by token",
 			zap.String("token", token),
 			zap.Error(result.Error),
 		)
-		return result.Error
+		return spanError(span, result.Error)
 	}
 	if result.RowsAffected == 0 {
-		pkg.Logger.Warn("No subscription found to unsubscribe by token", zap.String("token", token))
+		r.log(ctx).Warn("No subscription found to unsubscribe by token", zap.String("token", token))
 		return gorm.ErrRecordNotFound
 	}
-	pkg.Logger.Info("Unsubscribed by token", zap.String("token", token))
+	r.log(ctx).Info("Unsubscribed by token", zap.String("token", token))
 	return nil
 }
+
+func (r *PostgresRepo) CountSubscriptionsByState() (map[string]int64, error) {
+	var rows []struct {
+		Confirmed bool
+		Count     int64
+	}
+	err := r.db.Model(&SubscriptionDB{}).
+		Select("confirmed, count(*) AS count").
+		Group("confirmed").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("Failed to count subscriptions by state", zap.Error(err))
+		return nil, err
+	}
+
+	counts := map[string]int64{"confirmed": 0, "pending": 0}
+	for _, row := range rows {
+		if row.Confirmed {
+			counts["confirmed"] = row.Count
+		} else {
+			counts["pending"] = row.Count
+		}
+	}
+	return counts, nil
+}
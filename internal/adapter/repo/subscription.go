@@ -5,16 +5,24 @@ import (
 )
 
 type SubscriptionDB struct {
-	ID               int64      `gorm:"primaryKey"`
-	Email            string     `gorm:"size:255;not null"`
-	City             string     `gorm:"size:255;not null"`
-	Frequency        string     `gorm:"size:16;not null"`
-	Confirmed        bool       `gorm:"not null"`
-	ConfirmToken     string     `gorm:"size:255;not null"`
-	UnsubscribeToken string     `gorm:"size:255;not null"`
-	CreatedAt        time.Time  `gorm:"autoCreateTime"`
-	UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
-	LastSentAt       *time.Time `gorm:"column:last_sent_at"`
+	ID                  int64      `gorm:"primaryKey"`
+	Email               string     `gorm:"size:255;not null"`
+	City                string     `gorm:"size:255;not null"`
+	Frequency           string     `gorm:"size:16;not null"`
+	Confirmed           bool       `gorm:"not null"`
+	ConfirmToken        string     `gorm:"size:255;not null"`
+	UnsubscribeToken    string     `gorm:"size:255;not null"`
+	Language            string     `gorm:"size:8;not null;default:en"`
+	Channel             string     `gorm:"size:16;not null;default:email"`
+	Destination         string     `gorm:"size:512;not null;default:''"`
+	WebhookSecret       string     `gorm:"size:128;not null;default:''"`
+	UndeliverableAt     *time.Time `gorm:"column:undeliverable_at"`
+	UndeliverableReason string     `gorm:"size:512;not null;default:''"`
+	CreatedAt           time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"autoUpdateTime"`
+	LastSentAt          *time.Time `gorm:"column:last_sent_at"`
+	ClaimedBy           *string    `gorm:"column:claimed_by;size:255"`
+	ClaimedUntil        *time.Time `gorm:"column:claimed_until"`
 }
 
 func (SubscriptionDB) TableName() string {
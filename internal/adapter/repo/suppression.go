@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+)
+
+// SuppressionDB stores emails lowercased and trimmed, so lookups can compare
+// them directly.
+type SuppressionDB struct {
+	ID        int64     `gorm:"primaryKey"`
+	Email     string    `gorm:"size:255;not null;uniqueIndex:uq_suppressions_email"`
+	Reason    string    `gorm:"size:16;not null"`
+	Detail    string    `gorm:"type:text;not null;default:''"`
+	Source    string    `gorm:"size:32;not null;default:''"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (SuppressionDB) TableName() string {
+	return "suppressions"
+}
+
+func SuppressionToDomain(s *SuppressionDB) *model.Suppression {
+	return &model.Suppression{
+		ID:        s.ID,
+		Email:     s.Email,
+		Reason:    s.Reason,
+		Detail:    s.Detail,
+		Source:    s.Source,
+		CreatedAt: s.CreatedAt,
+	}
+}
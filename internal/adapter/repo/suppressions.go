@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostgresSuppressionRepo struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPostgresSuppressionRepo(db *gorm.DB, logger *zap.Logger) *PostgresSuppressionRepo {
+	return &PostgresSuppressionRepo{db: db, logger: pkg.OrNop(logger)}
+}
+
+func (r *PostgresSuppressionRepo) log(ctx context.Context) *zap.Logger {
+	return pkg.FromContext(ctx, r.logger)
+}
+
+func startSuppressionSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return startTableSpan(ctx, "PostgresSuppressionRepo", "suppressions", operation)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Suppress adds s to the list. An address that is already suppressed keeps
+// its first reason.
+func (r *PostgresSuppressionRepo) Suppress(ctx context.Context, s *model.Suppression) error {
+	ctx, span := startSuppressionSpan(ctx, "Suppress")
+	defer span.End()
+
+	row := SuppressionDB{Email: normalizeEmail(s.Email), Reason: s.Reason, Detail: s.Detail, Source: s.Source}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "email"}}, DoNothing: true}).
+		Create(&row).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to suppress email", zap.String("email", s.Email), zap.Error(err))
+		return spanError(span, err)
+	}
+	r.log(ctx).Info("Email suppressed", zap.String("email", s.Email), zap.String("reason", s.Reason))
+	return nil
+}
+
+func (r *PostgresSuppressionRepo) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	ctx, span := startSuppressionSpan(ctx, "IsSuppressed")
+	defer span.End()
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&SuppressionDB{}).
+		Where("email = ?", normalizeEmail(email)).
+		Count(&count).Error
+	if err != nil {
+		r.log(ctx).Error("Failed to check suppression", zap.String("email", email), zap.Error(err))
+		return false, spanError(span, err)
+	}
+	return count > 0, nil
+}
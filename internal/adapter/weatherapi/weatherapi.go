@@ -1,20 +1,49 @@
 package weatherapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 )
 
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/adapter/weatherapi")
+
 type WeatherAPIProvider struct {
 	apiKey string
+	client *http.Client
+	logger *zap.Logger
+
+	// failures counts calls that failed in a row for the readiness check.
+	// Nothing is held back while it grows. reason is the metrics label of the
+	// latest failure rather than the error, which carries the API key in its
+	// URL.
+	mu          sync.Mutex
+	failures    int
+	reason      string
+	lastSuccess time.Time
+	lastFailure time.Time
 }
 
-func NewWeatherAPIProvider(apiKey string) *WeatherAPIProvider {
-	return &WeatherAPIProvider{apiKey: apiKey}
+func NewWeatherAPIProvider(apiKey string, logger *zap.Logger) *WeatherAPIProvider {
+	return &WeatherAPIProvider{
+		apiKey: apiKey,
+		logger: pkg.OrNop(logger),
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
 }
 
 type weatherAPIResponse struct {
@@ -27,36 +56,69 @@ type weatherAPIResponse struct {
 	} `json:"current"`
 }
 
-func (w *WeatherAPIProvider) GetWeather(city string) (*model.Weather, error) {
+func (w *WeatherAPIProvider) GetWeather(ctx context.Context, city string) (*model.Weather, error) {
+	return w.GetLocalizedWeather(ctx, city, "")
+}
+
+// GetLocalizedWeather asks WeatherAPI for the condition text in lang. An empty
+// lang leaves it in English.
+func (w *WeatherAPIProvider) GetLocalizedWeather(ctx context.Context, city, lang string) (*model.Weather, error) {
+	ctx, span := tracer.Start(ctx, "WeatherAPIProvider.GetWeather")
+	defer span.End()
+	span.SetAttributes(attribute.String("weather.city", city), attribute.String("weather.lang", lang))
+
 	url := fmt.Sprintf("https://api.weatherapi.com/v1/current.json?key=%s&q=%s", w.apiKey, city)
-	resp, err := http.Get(url)
+	if lang != "" && lang != "en" {
+		url += "&lang=" + lang
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	start := time.Now()
+	resp, err := w.client.Do(req)
 	if err != nil {
-		pkg.Logger.Error("Failed to make weather API request",
+		observeCall(start, "error", "transport")
+		w.recordFailure("transport")
+		pkg.FromContext(ctx, w.logger).Error("Failed to make weather API request",
 			zap.String("city", city),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, tracing.Error(span, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		pkg.Logger.Warn("Non-200 status from weather API",
+		pkg.FromContext(ctx, w.logger).Warn("Non-200 status from weather API",
 			zap.String("city", city),
 			zap.Int("status_code", resp.StatusCode),
 		)
-		return nil, fmt.Errorf("failed to get weather: %s", resp.Status)
+		err := fmt.Errorf("failed to get weather: %s", resp.Status)
+		if isProviderFailure(resp.StatusCode) {
+			reason := "status_" + strconv.Itoa(resp.StatusCode)
+			observeCall(start, "error", reason)
+			w.recordFailure(reason)
+		} else {
+			observeCall(start, "not_found", "")
+			w.recordSuccess()
+		}
+		return nil, tracing.Error(span, err)
 	}
 
 	var data weatherAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		pkg.Logger.Error("Failed to decode weather API response",
+		pkg.FromContext(ctx, w.logger).Error("Failed to decode weather API response",
 			zap.String("city", city),
 			zap.Error(err),
 		)
-		return nil, err
+		observeCall(start, "error", "decode")
+		w.recordFailure("decode")
+		return nil, tracing.Error(span, err)
 	}
+	observeCall(start, "success", "")
+	w.recordSuccess()
 
-	pkg.Logger.Info("Successfully fetched weather",
+	pkg.FromContext(ctx, w.logger).Info("Successfully fetched weather",
 		zap.String("city", city),
 		zap.Float64("temp_c", data.Current.TempC),
 		zap.Int("humidity", data.Current.Humidity),
@@ -69,3 +131,51 @@ func (w *WeatherAPIProvider) GetWeather(city string) (*model.Weather, error) {
 		Description: data.Current.Condition.Text,
 	}, nil
 }
+
+const providerName = "weatherapi"
+
+func observeCall(start time.Time, outcome, reason string) {
+	metrics.WeatherProviderDuration.WithLabelValues(providerName, outcome).Observe(time.Since(start).Seconds())
+	if reason != "" {
+		metrics.WeatherProviderErrors.WithLabelValues(providerName, reason).Inc()
+	}
+}
+
+// ConsecutiveFailures returns the number of calls that failed since the last
+// successful one and the reason of the latest failure.
+func (w *WeatherAPIProvider) ConsecutiveFailures() (int, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.failures, w.reason
+}
+
+// LastCalls returns when a call last succeeded and last failed.
+func (w *WeatherAPIProvider) LastCalls() (success, failure time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastSuccess, w.lastFailure
+}
+
+func (w *WeatherAPIProvider) recordSuccess() {
+	now := time.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures = 0
+	w.reason = ""
+	w.lastSuccess = now
+}
+
+func (w *WeatherAPIProvider) recordFailure(reason string) {
+	now := time.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures++
+	w.reason = reason
+	w.lastFailure = now
+}
+
+// isProviderFailure tells provider outages apart from lookups of unknown
+// cities, which weatherapi.com answers with 400.
+func isProviderFailure(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
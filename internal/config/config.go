@@ -1,9 +1,11 @@
 package config
 
 import (
-	"github.com/l4ndm1nes/Weather-API-Application/pkg"
-	"go.uber.org/zap"
 	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 type Config struct {
@@ -20,21 +22,128 @@ type Config struct {
 	SMTPFrom      string
 	WeatherAPIKey string
 	BaseURL       string
+
+	SMTPTLSMode     string
+	SMTPCAFile      string
+	SMTPSkipVerify  bool
+	SMTPPoolSize    int
+	SMTPIdleTimeout time.Duration
+	SMTPTimeout     time.Duration
+
+	MailProvider       string
+	MailAPIURL         string
+	MailAPIKey         string
+	MailgunDomain      string
+	AWSRegion          string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+
+	FormTokenSecret  string
+	FormMinFillTime  time.Duration
+	FormTokenMaxAge  time.Duration
+	CaptchaProvider  string
+	CaptchaSecret    string
+	CaptchaSiteKey   string
+	CaptchaVerifyURL string
+
+	WeatherCacheTTL  time.Duration
+	WeatherCacheSize int
+
+	TracingExporter    string
+	TracingServiceName string
+	TracingSampleRatio float64
+
+	LogLevel           string
+	LogFormat          string
+	LogSampling        bool
+	LogComponentLevels string
+	AdminToken         string
+
+	MailJobConcurrency int
+	MailJobBatchSize   int
+	MailJobLock        bool
+	MailJobClaimLease  time.Duration
+	MailJobCatchUp     time.Duration
+
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxMaxAttempts  int
+	OutboxBaseBackoff  time.Duration
+	OutboxMaxBackoff   time.Duration
+
+	TelegramBotToken      string
+	TelegramAPIURL        string
+	SlackWebhookBaseURL   string
+	DiscordWebhookBaseURL string
+	WebhookBaseURL        string
+
+	EmailEventsToken string
+
+	HTTPAddr           string
+	ShutdownTimeout    time.Duration
+	JobShutdownTimeout time.Duration
 }
 
-func LoadConfig() *Config {
+func LoadConfig(logger *zap.Logger) *Config {
 	getEnv := func(key, def string) string {
 		val := os.Getenv(key)
 		if val != "" {
 			return val
 		}
 		if def != "" {
-			pkg.Logger.Warn("env var not set, using default", zap.String("env_var", key), zap.String("default", def))
+			logger.Warn("env var not set, using default", zap.String("env_var", key), zap.String("default", def))
 			return def
 		}
-		pkg.Logger.Fatal("missing required env variable", zap.String("env_var", key))
+		logger.Fatal("missing required env variable", zap.String("env_var", key))
 		return ""
 	}
+	getOptionalEnv := func(key string) string {
+		return os.Getenv(key)
+	}
+	getEnvDuration := func(key, def string) time.Duration {
+		raw := getEnv(key, def)
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			logger.Fatal("invalid duration env variable", zap.String("env_var", key), zap.String("value", raw), zap.Error(err))
+		}
+		return d
+	}
+
+	getEnvFloat := func(key, def string) float64 {
+		raw := getEnv(key, def)
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			logger.Fatal("invalid number env variable", zap.String("env_var", key), zap.String("value", raw), zap.Error(err))
+		}
+		return f
+	}
+
+	getEnvInt := func(key, def string) int {
+		raw := getEnv(key, def)
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			logger.Fatal("invalid integer env variable", zap.String("env_var", key), zap.String("value", raw), zap.Error(err))
+		}
+		return n
+	}
+
+	getEnvBool := func(key, def string) bool {
+		raw := getEnv(key, def)
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			logger.Fatal("invalid boolean env variable", zap.String("env_var", key), zap.String("value", raw), zap.Error(err))
+		}
+		return b
+	}
+
+	// The SMTP relay settings are only required when mail goes over SMTP.
+	mailProvider := getEnv("MAIL_PROVIDER", "smtp")
+	getSMTPEnv := func(key string) string {
+		if mailProvider != "smtp" {
+			return getOptionalEnv(key)
+		}
+		return getEnv(key, "")
+	}
 
 	return &Config{
 		DBHost:        getEnv("DB_HOST", ""),
@@ -43,12 +152,72 @@ func LoadConfig() *Config {
 		DBPassword:    getEnv("DB_PASSWORD", ""),
 		DBName:        getEnv("DB_NAME", ""),
 		DB_SSLMODE:    getEnv("DB_SSLMODE", ""),
-		SMTPHost:      getEnv("SMTP_HOST", ""),
+		SMTPHost:      getSMTPEnv("SMTP_HOST"),
 		SMTPPort:      getEnv("SMTP_PORT", "587"),
-		SMTPUser:      getEnv("SMTP_USER", ""),
-		SMTPPass:      getEnv("SMTP_PASS", ""),
+		SMTPUser:      getSMTPEnv("SMTP_USER"),
+		SMTPPass:      getSMTPEnv("SMTP_PASS"),
 		SMTPFrom:      getEnv("SMTP_FROM", ""),
 		WeatherAPIKey: getEnv("WEATHER_API_KEY", ""),
 		BaseURL:       getEnv("BASE_URL", "http://localhost:8080"),
+
+		SMTPTLSMode:     getEnv("SMTP_TLS_MODE", "auto"),
+		SMTPCAFile:      getOptionalEnv("SMTP_TLS_CA_FILE"),
+		SMTPSkipVerify:  getEnvBool("SMTP_TLS_SKIP_VERIFY", "false"),
+		SMTPPoolSize:    getEnvInt("SMTP_POOL_SIZE", "2"),
+		SMTPIdleTimeout: getEnvDuration("SMTP_IDLE_TIMEOUT", "30s"),
+		SMTPTimeout:     getEnvDuration("SMTP_TIMEOUT", "30s"),
+
+		MailProvider:       mailProvider,
+		MailAPIURL:         getOptionalEnv("MAIL_API_URL"),
+		MailAPIKey:         getOptionalEnv("MAIL_API_KEY"),
+		MailgunDomain:      getOptionalEnv("MAILGUN_DOMAIN"),
+		AWSRegion:          getOptionalEnv("AWS_REGION"),
+		AWSAccessKeyID:     getOptionalEnv("AWS_ACCESS_KEY_ID"),
+		AWSSecretAccessKey: getOptionalEnv("AWS_SECRET_ACCESS_KEY"),
+
+		FormTokenSecret:  getOptionalEnv("FORM_TOKEN_SECRET"),
+		FormMinFillTime:  getEnvDuration("FORM_MIN_FILL_TIME", "3s"),
+		FormTokenMaxAge:  getEnvDuration("FORM_TOKEN_MAX_AGE", "2h"),
+		CaptchaProvider:  getOptionalEnv("CAPTCHA_PROVIDER"),
+		CaptchaSecret:    getOptionalEnv("CAPTCHA_SECRET"),
+		CaptchaSiteKey:   getOptionalEnv("CAPTCHA_SITE_KEY"),
+		CaptchaVerifyURL: getOptionalEnv("CAPTCHA_VERIFY_URL"),
+
+		WeatherCacheTTL:  getEnvDuration("WEATHER_CACHE_TTL", "5m"),
+		WeatherCacheSize: getEnvInt("WEATHER_CACHE_SIZE", "1000"),
+
+		TracingExporter:    getEnv("TRACING_EXPORTER", "none"),
+		TracingServiceName: getEnv("TRACING_SERVICE_NAME", "weather-api"),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", "1"),
+
+		LogLevel:           getEnv("LOG_LEVEL", "info"),
+		LogFormat:          getEnv("LOG_FORMAT", "json"),
+		LogSampling:        getEnvBool("LOG_SAMPLING", "true"),
+		LogComponentLevels: getOptionalEnv("LOG_LEVELS"),
+		AdminToken:         getOptionalEnv("ADMIN_TOKEN"),
+
+		MailJobConcurrency: getEnvInt("MAIL_JOB_CONCURRENCY", "4"),
+		MailJobBatchSize:   getEnvInt("MAIL_JOB_BATCH_SIZE", "500"),
+		MailJobLock:        getEnvBool("MAIL_JOB_LOCK", "true"),
+		MailJobClaimLease:  getEnvDuration("MAIL_JOB_CLAIM_LEASE", "15m"),
+		MailJobCatchUp:     getEnvDuration("MAIL_JOB_CATCHUP_GRACE", "45m"),
+
+		OutboxPollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", "5s"),
+		OutboxBatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", "50"),
+		OutboxMaxAttempts:  getEnvInt("OUTBOX_MAX_ATTEMPTS", "8"),
+		OutboxBaseBackoff:  getEnvDuration("OUTBOX_BASE_BACKOFF", "30s"),
+		OutboxMaxBackoff:   getEnvDuration("OUTBOX_MAX_BACKOFF", "1h"),
+
+		TelegramBotToken:      getOptionalEnv("TELEGRAM_BOT_TOKEN"),
+		TelegramAPIURL:        getEnv("TELEGRAM_API_URL", "https://api.telegram.org"),
+		SlackWebhookBaseURL:   getEnv("SLACK_WEBHOOK_BASE_URL", "https://hooks.slack.com/services"),
+		DiscordWebhookBaseURL: getEnv("DISCORD_WEBHOOK_BASE_URL", "https://discord.com/api/webhooks"),
+		WebhookBaseURL:        getOptionalEnv("WEBHOOK_BASE_URL"),
+
+		EmailEventsToken: getOptionalEnv("EMAIL_EVENTS_TOKEN"),
+
+		HTTPAddr:           getEnv("HTTP_ADDR", ":8080"),
+		ShutdownTimeout:    getEnvDuration("SHUTDOWN_TIMEOUT", "20s"),
+		JobShutdownTimeout: getEnvDuration("JOB_SHUTDOWN_TIMEOUT", "60s"),
 	}
 }
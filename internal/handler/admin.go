@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
+	"go.uber.org/zap"
+)
+
+type LogLevelController interface {
+	Levels() map[string]string
+	SetLevel(component, level string) error
+}
+
+type SetLogLevelRequest struct {
+	Component string `json:"component"`
+	Level     string `json:"level" binding:"required"`
+}
+
+type AdminHandler struct {
+	LogLevels LogLevelController
+	logger    *zap.Logger
+}
+
+func NewAdminHandler(logLevels LogLevelController, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{LogLevels: logLevels, logger: pkg.OrNop(logger)}
+}
+
+func (h *AdminHandler) GetLogLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"levels": h.LogLevels.Levels()})
+}
+
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	component := req.Component
+	if component == "" {
+		component = pkg.RootComponent
+	}
+	if err := h.LogLevels.SetLevel(component, req.Level); err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid log level", err)
+		return
+	}
+	pkg.FromContext(c.Request.Context(), h.logger).Info("log level changed",
+		zap.String("component", component),
+		zap.String("level", req.Level),
+	)
+	c.JSON(http.StatusOK, gin.H{"levels": h.LogLevels.Levels()})
+}
+
+func RegisterAdminRoutes(r *gin.Engine, adminToken string, adminHandler *AdminHandler) *gin.RouterGroup {
+	admin := r.Group("/admin", middleware.AdminAuth(adminToken, adminHandler.logger))
+	{
+		admin.GET("/log-level", adminHandler.GetLogLevels)
+		admin.PUT("/log-level", adminHandler.SetLogLevel)
+	}
+	return admin
+}
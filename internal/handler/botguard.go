@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/formtoken"
+)
+
+var (
+	ErrHoneypot      = errors.New("honeypot field filled")
+	ErrFormToken     = errors.New("invalid form token")
+	ErrCaptchaFailed = errors.New("captcha verification failed")
+)
+
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// BotGuard bundles the subscribe form defenses: a honeypot field, a signed
+// render timestamp with a minimum fill time and an optional captcha.
+type BotGuard struct {
+	Signer         *formtoken.Signer
+	MinFillTime    time.Duration
+	MaxTokenAge    time.Duration
+	Captcha        CaptchaVerifier
+	CaptchaSiteKey string
+	CaptchaType    string
+}
+
+func NewBotGuard(signer *formtoken.Signer, minFillTime, maxTokenAge time.Duration) *BotGuard {
+	return &BotGuard{
+		Signer:      signer,
+		MinFillTime: minFillTime,
+		MaxTokenAge: maxTokenAge,
+	}
+}
+
+func (g *BotGuard) WithCaptcha(kind, siteKey string, verifier CaptchaVerifier) *BotGuard {
+	g.CaptchaType = kind
+	g.CaptchaSiteKey = siteKey
+	g.Captcha = verifier
+	return g
+}
+
+func (g *BotGuard) IssueFormToken() string {
+	return g.Signer.Issue(time.Now())
+}
+
+func (g *BotGuard) Check(ctx context.Context, req *SubscribeRequest, remoteIP string) error {
+	if req.Website != "" {
+		return ErrHoneypot
+	}
+	if err := g.Signer.Verify(req.FormToken, time.Now(), g.MinFillTime, g.MaxTokenAge); err != nil {
+		return errors.Join(ErrFormToken, err)
+	}
+	if g.Captcha != nil {
+		if err := g.Captcha.Verify(ctx, req.CaptchaToken, remoteIP); err != nil {
+			return errors.Join(ErrCaptchaFailed, err)
+		}
+	}
+	return nil
+}
@@ -9,8 +9,56 @@ func ToDomainFromRequest(req *SubscribeRequest) *model.Subscription {
 		return nil
 	}
 	return &model.Subscription{
-		Email:     req.Email,
-		City:      req.City,
-		Frequency: req.Frequency,
+		Email:       req.Email,
+		City:        req.City,
+		Frequency:   req.Frequency,
+		Language:    req.Language,
+		Channel:     req.Channel,
+		Destination: req.Destination,
+	}
+}
+
+func ToJobRunResponse(run *model.JobRun) JobRunResponse {
+	return JobRunResponse{
+		ID:         run.ID,
+		Name:       run.Name,
+		WorkerID:   run.WorkerID,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Total:      run.Total,
+		Sent:       run.Sent,
+		Skipped:    run.Skipped,
+		Failed:     run.Failed,
+		Error:      run.Error,
+	}
+}
+
+func ToJobDeliveryResponse(d *model.JobDelivery) JobDeliveryResponse {
+	return JobDeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		City:           d.City,
+		Status:         d.Status,
+		Reason:         d.Reason,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+// ToOutboxMessageResponse leaves out the confirmation token and the email body.
+func ToOutboxMessageResponse(m *model.OutboxMessage) OutboxMessageResponse {
+	return OutboxMessageResponse{
+		ID:            m.ID,
+		Kind:          m.Kind,
+		Recipient:     m.Recipient,
+		City:          m.Payload.City,
+		DeliveryID:    m.DeliveryID,
+		Status:        m.Status,
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		LastError:     m.LastError,
+		SentAt:        m.SentAt,
+		CreatedAt:     m.CreatedAt,
 	}
 }
@@ -1,7 +1,57 @@
 package handler
 
+import "time"
+
 type SubscribeRequest struct {
 	Email     string `json:"email" form:"email" binding:"required,email"`
 	City      string `json:"city" form:"city" binding:"required"`
 	Frequency string `json:"frequency" form:"frequency" binding:"required,oneof=hourly daily"`
+	// Language defaults to the one preferred in Accept-Language.
+	Language string `json:"language" form:"language"`
+	// Channel defaults to email. Destination is the chat ID or webhook URL
+	// weather updates go to; email subscriptions may leave it empty.
+	Channel     string `json:"channel" form:"channel" binding:"omitempty,oneof=email telegram slack discord webhook"`
+	Destination string `json:"destination" form:"destination" binding:"max=512"`
+
+	Website      string `json:"website" form:"website"`
+	FormToken    string `json:"form_token" form:"form_token"`
+	CaptchaToken string `json:"captcha_token" form:"captcha_token"`
+}
+
+type JobRunResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	WorkerID   string     `json:"worker_id"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Total      int        `json:"total"`
+	Sent       int        `json:"sent"`
+	Skipped    int        `json:"skipped"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type JobDeliveryResponse struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	City           string    `json:"city"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type OutboxMessageResponse struct {
+	ID            int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	Recipient     string     `json:"recipient"`
+	City          string     `json:"city,omitempty"`
+	DeliveryID    *int64     `json:"delivery_id,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
 }
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
+	"go.uber.org/zap"
+)
+
+const (
+	EmailEventsGeneric  = "generic"
+	EmailEventsSendGrid = "sendgrid"
+)
+
+type EmailEventProcessor interface {
+	Process(ctx context.Context, source string, events []model.EmailEvent) (int, error)
+}
+
+// GenericEmailEvent is the provider-neutral format, for relays without a
+// vendor parser and for forwarding events from scripts.
+type GenericEmailEvent struct {
+	Type       string `json:"type"`
+	Email      string `json:"email"`
+	BounceType string `json:"bounce_type"`
+	Reason     string `json:"reason"`
+}
+
+// SendGridEvent holds the fields of the SendGrid event webhook this handler
+// reads. Delivery and engagement events are ignored.
+type SendGridEvent struct {
+	Event  string `json:"event"`
+	Email  string `json:"email"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+type EmailEventsHandler struct {
+	Events EmailEventProcessor
+	logger *zap.Logger
+}
+
+func NewEmailEventsHandler(events EmailEventProcessor, logger *zap.Logger) *EmailEventsHandler {
+	return &EmailEventsHandler{Events: events, logger: pkg.OrNop(logger)}
+}
+
+func (h *EmailEventsHandler) Receive(c *gin.Context) {
+	provider := c.Param("provider")
+	var events []model.EmailEvent
+	var err error
+	switch provider {
+	case EmailEventsGeneric:
+		events, err = bindGenericEvents(c)
+	case EmailEventsSendGrid:
+		events, err = bindSendGridEvents(c)
+	default:
+		respondError(c, h.logger, http.StatusNotFound, "Unknown provider", errors.New("provider must be generic or sendgrid"))
+		return
+	}
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	suppressed, err := h.Events.Process(c.Request.Context(), provider, events)
+	if err != nil {
+		// A 5xx makes the provider retry the batch; suppressing is idempotent.
+		respondError(c, h.logger, http.StatusInternalServerError, "Failed to process email events", err)
+		return
+	}
+	respondSuccess(c, h.logger, http.StatusOK, gin.H{"received": len(events), "suppressed": suppressed})
+}
+
+func bindGenericEvents(c *gin.Context) ([]model.EmailEvent, error) {
+	var req []GenericEmailEvent
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+	events := make([]model.EmailEvent, 0, len(req))
+	for _, e := range req {
+		events = append(events, model.EmailEvent{
+			Type:   e.Type,
+			Email:  e.Email,
+			Soft:   e.BounceType == "soft",
+			Detail: e.Reason,
+		})
+	}
+	return events, nil
+}
+
+func bindSendGridEvents(c *gin.Context) ([]model.EmailEvent, error) {
+	var req []SendGridEvent
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+	events := make([]model.EmailEvent, 0, len(req))
+	for _, e := range req {
+		var typ string
+		switch e.Event {
+		case "bounce":
+			typ = model.EmailEventBounce
+		case "spamreport":
+			typ = model.EmailEventComplaint
+		default:
+			continue
+		}
+		events = append(events, model.EmailEvent{
+			Type:  typ,
+			Email: e.Email,
+			// "blocked" bounces are temporary refusals by the receiving server.
+			Soft:   e.Type == "blocked",
+			Detail: e.Reason,
+		})
+	}
+	return events, nil
+}
+
+func RegisterEmailEventRoutes(r *gin.Engine, token string, eventsHandler *EmailEventsHandler) {
+	r.POST("/api/email-events/:provider",
+		middleware.WebhookAuth(token, eventsHandler.logger),
+		eventsHandler.Receive,
+	)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/health"
+)
+
+type HealthHandler struct {
+	Registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{Registry: registry}
+}
+
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.Registry.Run(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == health.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func RegisterHealthRoutes(r *gin.Engine, healthHandler *HealthHandler) {
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+}
@@ -2,26 +2,28 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg"
 	"go.uber.org/zap"
 )
 
-func respondError(c *gin.Context, status int, message string, err error) {
-	entry := pkg.Logger.With(zap.Int("status", status), zap.String("message", message))
+func respondError(c *gin.Context, logger *zap.Logger, status int, message string, err error) {
+	entry := pkg.FromContext(c.Request.Context(), logger).With(zap.Int("status", status), zap.String("message", message))
 	if err != nil {
 		entry = entry.With(zap.Error(err))
 	}
 	entry.Warn("error occurred")
 
-	c.Status(status)
+	c.JSON(status, gin.H{"error": i18n.T(requestLanguage(c), message)})
 }
 
-func respondSuccess(c *gin.Context, status int, payload gin.H) {
-	entry := pkg.Logger.With(zap.Int("status", status))
-	if payload != nil {
-		entry = entry.With(zap.Any("response", payload))
-	}
-	entry.Info("successful response")
+// requestLanguage is the language the client prefers in Accept-Language.
+func requestLanguage(c *gin.Context) string {
+	return i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+func respondSuccess(c *gin.Context, logger *zap.Logger, status int, payload gin.H) {
+	pkg.FromContext(c.Request.Context(), logger).Debug("successful response", zap.Int("status", status))
 	if payload == nil {
 		c.Status(status)
 		return
@@ -1,106 +1,197 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"gorm.io/gorm"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
+	"go.uber.org/zap"
 )
 
 type SubscriptionService interface {
-	Subscribe(sub *model.Subscription) (*model.Subscription, error)
-	ConfirmSubscription(token string) error
-	Unsubscribe(token string) error
+	Subscribe(ctx context.Context, sub *model.Subscription) (*model.Subscription, error)
+	ConfirmSubscription(ctx context.Context, token string) error
+	Unsubscribe(ctx context.Context, token string) error
 }
 
 type WeatherService interface {
-	GetWeather(city string) (*model.Weather, error)
+	GetLocalizedWeather(ctx context.Context, city, lang string) (*model.Weather, error)
 }
 
 type SubscriptionHandler struct {
 	SubService     SubscriptionService
 	WeatherService WeatherService
+	BotGuard       *BotGuard
+	logger         *zap.Logger
 }
 
-func NewSubscriptionHandler(subService SubscriptionService, weatherService WeatherService) *SubscriptionHandler {
+func NewSubscriptionHandler(subService SubscriptionService, weatherService WeatherService, logger *zap.Logger) *SubscriptionHandler {
 	return &SubscriptionHandler{
 		SubService:     subService,
 		WeatherService: weatherService,
+		logger:         pkg.OrNop(logger),
 	}
 }
 
 func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
 	var req SubscribeRequest
 	if err := c.ShouldBind(&req); err != nil {
-		respondError(c, http.StatusBadRequest, "Invalid input", err)
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid input", err)
 		return
 	}
-	_, err := h.SubService.Subscribe(ToDomainFromRequest(&req))
+	if h.BotGuard != nil {
+		if err := h.BotGuard.Check(c.Request.Context(), &req, c.ClientIP()); err != nil {
+			if errors.Is(err, ErrHoneypot) {
+				respondSuccess(c, h.logger, http.StatusOK, nil)
+				return
+			}
+			respondError(c, h.logger, http.StatusBadRequest, "Bot check failed", err)
+			return
+		}
+	}
+	sub := ToDomainFromRequest(&req)
+	if sub.Language == "" {
+		sub.Language = requestLanguage(c)
+	}
+	created, err := h.SubService.Subscribe(c.Request.Context(), sub)
 	if err != nil {
 		if err.Error() == "email already subscribed" {
-			respondError(c, http.StatusConflict, "Email already subscribed", err)
+			respondError(c, h.logger, http.StatusConflict, "Email already subscribed", err)
+		} else if errors.Is(err, notify.ErrUnknownChannel) || errors.Is(err, notify.ErrInvalidDestination) {
+			respondError(c, h.logger, http.StatusBadRequest, "Invalid destination", err)
 		} else {
-			respondError(c, http.StatusBadRequest, "Invalid input", err)
+			// Suppressed addresses land here on purpose, so the endpoint does
+			// not reveal which addresses bounced or complained; the reason is
+			// logged.
+			respondError(c, h.logger, http.StatusBadRequest, "Invalid input", err)
 		}
 		return
 	}
 
-	respondSuccess(c, http.StatusOK, nil)
+	// The secret also goes to the webhook in the challenge sent on
+	// confirmation; returning it lets the subscriber configure the receiver
+	// before confirming.
+	if created != nil && created.WebhookSecret != "" {
+		respondSuccess(c, h.logger, http.StatusOK, gin.H{"webhook_secret": created.WebhookSecret})
+		return
+	}
+	respondSuccess(c, h.logger, http.StatusOK, nil)
 }
 
 func (h *SubscriptionHandler) ConfirmSubscription(c *gin.Context) {
 	token, ok := getStringFromCtx(c, "token")
 	if !ok {
-		respondError(c, http.StatusBadRequest, "Invalid token", nil)
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid token", nil)
 		return
 	}
 
-	err := h.SubService.ConfirmSubscription(token)
+	err := h.SubService.ConfirmSubscription(c.Request.Context(), token)
 	if err != nil {
 		if err.Error() == "already confirmed" {
-			respondError(c, http.StatusBadRequest, "Subscription already confirmed", nil)
+			respondError(c, h.logger, http.StatusBadRequest, "Subscription already confirmed", nil)
 		} else if err.Error() == "subscription not found" {
-			respondError(c, http.StatusNotFound, "Token not found", err)
+			respondError(c, h.logger, http.StatusNotFound, "Token not found", err)
+		} else if errors.Is(err, notify.ErrVerificationFailed) {
+			respondError(c, h.logger, http.StatusBadRequest, "Webhook verification failed", err)
 		} else {
-			respondError(c, http.StatusBadRequest, "Error confirming subscription", err)
+			respondError(c, h.logger, http.StatusBadRequest, "Error confirming subscription", err)
 		}
 		return
 	}
 
-	respondSuccess(c, http.StatusOK, nil)
+	respondSuccess(c, h.logger, http.StatusOK, nil)
 }
 
+// UnsubscribePage asks for confirmation instead of unsubscribing, so link
+// scanners that follow the link in an email do not unsubscribe anyone.
+func (h *SubscriptionHandler) UnsubscribePage(c *gin.Context) {
+	if _, ok := getStringFromCtx(c, "token"); !ok {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid token", nil)
+		return
+	}
+	h.renderUnsubscribePage(c, http.StatusOK, templates.UnsubscribeConfirm)
+}
+
+// Unsubscribe serves both the RFC 8058 one-click POST that mail providers send
+// with the body "List-Unsubscribe=One-Click" and the form on the confirmation
+// page. Browsers get a page back, other clients only the status.
 func (h *SubscriptionHandler) Unsubscribe(c *gin.Context) {
 	token, ok := getStringFromCtx(c, "token")
 	if !ok {
-		respondError(c, http.StatusBadRequest, "Invalid token", nil)
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid token", nil)
 		return
 	}
+	page := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
 
-	err := h.SubService.Unsubscribe(token)
+	err := h.SubService.Unsubscribe(c.Request.Context(), token)
 	if err == nil {
-		respondSuccess(c, http.StatusOK, nil)
+		if page {
+			h.renderUnsubscribePage(c, http.StatusOK, templates.UnsubscribeDone)
+			return
+		}
+		respondSuccess(c, h.logger, http.StatusOK, nil)
 		return
 	}
 
 	if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "subscription not found" {
-		respondError(c, http.StatusNotFound, "Token not found", err)
+		if page {
+			h.renderUnsubscribePage(c, http.StatusNotFound, templates.UnsubscribeNotFound)
+			return
+		}
+		respondError(c, h.logger, http.StatusNotFound, "Token not found", err)
 		return
 	}
 
-	respondError(c, http.StatusBadRequest, "Error unsubscribing", err)
+	respondError(c, h.logger, http.StatusBadRequest, "Error unsubscribing", err)
+}
+
+func (h *SubscriptionHandler) renderUnsubscribePage(c *gin.Context, status int, state string) {
+	lang := requestLanguage(c)
+	page, err := templates.RenderPage(lang, templates.UnsubscribePage, templates.UnsubscribePageData{
+		Lang:   lang,
+		State:  state,
+		Action: c.Request.URL.Path,
+	})
+	if err != nil {
+		respondError(c, h.logger, http.StatusInternalServerError, "Error rendering page", err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", page)
+}
+
+func (h *SubscriptionHandler) FormToken(c *gin.Context) {
+	if h.BotGuard == nil {
+		respondSuccess(c, h.logger, http.StatusOK, gin.H{})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	respondSuccess(c, h.logger, http.StatusOK, gin.H{
+		"form_token":       h.BotGuard.IssueFormToken(),
+		"captcha_type":     h.BotGuard.CaptchaType,
+		"captcha_site_key": h.BotGuard.CaptchaSiteKey,
+	})
 }
 
 func (h *SubscriptionHandler) GetWeather(c *gin.Context) {
 	city := c.Query("city")
 	if city == "" {
-		respondError(c, http.StatusBadRequest, "Invalid request", nil)
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid request", nil)
 		return
 	}
-	weather, err := h.WeatherService.GetWeather(city)
+	lang := c.Query("lang")
+	if lang == "" {
+		lang = requestLanguage(c)
+	}
+	weather, err := h.WeatherService.GetLocalizedWeather(c.Request.Context(), city, i18n.Normalize(lang))
 	if err == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"temperature": weather.Temperature,
@@ -109,20 +200,25 @@ func (h *SubscriptionHandler) GetWeather(c *gin.Context) {
 		})
 		return
 	}
-	respondError(c, http.StatusNotFound, "City not found", err)
+	respondError(c, h.logger, http.StatusNotFound, "City not found", err)
 }
 
 func RegisterRoutes(r *gin.Engine, subHandler *SubscriptionHandler) {
 	api := r.Group("/api")
 	{
+		api.GET("/form-token", subHandler.FormToken)
 		api.POST("/subscribe", subHandler.Subscribe)
 		api.GET("/weather", subHandler.GetWeather)
 		api.GET("/confirm/:token",
-			middleware.TokenUUIDRequiredMiddleware("token", "Invalid token"),
+			middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", subHandler.logger),
 			subHandler.ConfirmSubscription,
 		)
 		api.GET("/unsubscribe/:token",
-			middleware.TokenUUIDRequiredMiddleware("token", "Invalid token"),
+			middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", subHandler.logger),
+			subHandler.UnsubscribePage,
+		)
+		api.POST("/unsubscribe/:token",
+			middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", subHandler.logger),
 			subHandler.Unsubscribe,
 		)
 	}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultJobsLimit       = 50
+	defaultDeliveriesLimit = 200
+	maxListLimit           = 1000
+)
+
+type JobRunReader interface {
+	ListRuns(ctx context.Context, beforeID int64, limit int) ([]*model.JobRun, error)
+	GetRun(ctx context.Context, id int64) (*model.JobRun, error)
+	ListDeliveries(ctx context.Context, runID, afterID int64, limit int) ([]*model.JobDelivery, error)
+}
+
+type JobHandler struct {
+	Runs   JobRunReader
+	logger *zap.Logger
+}
+
+func NewJobHandler(runs JobRunReader, logger *zap.Logger) *JobHandler {
+	return &JobHandler{Runs: runs, logger: pkg.OrNop(logger)}
+}
+
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	before, err := queryInt(c, "before", 0)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid before", err)
+		return
+	}
+	limit, err := queryLimit(c, defaultJobsLimit)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	runs, err := h.Runs.ListRuns(c.Request.Context(), before, limit)
+	if err != nil {
+		respondError(c, h.logger, http.StatusInternalServerError, "Failed to list jobs", err)
+		return
+	}
+	resp := make([]JobRunResponse, 0, len(runs))
+	for _, run := range runs {
+		resp = append(resp, ToJobRunResponse(run))
+	}
+	payload := gin.H{"jobs": resp}
+	if len(runs) == limit {
+		payload["next_before"] = runs[len(runs)-1].ID
+	}
+	respondSuccess(c, h.logger, http.StatusOK, payload)
+}
+
+func (h *JobHandler) GetJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+	after, err := queryInt(c, "after", 0)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid after", err)
+		return
+	}
+	limit, err := queryLimit(c, defaultDeliveriesLimit)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	run, err := h.Runs.GetRun(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, h.logger, http.StatusNotFound, "Job not found", err)
+		return
+	}
+	if err != nil {
+		respondError(c, h.logger, http.StatusInternalServerError, "Failed to get job", err)
+		return
+	}
+	deliveries, err := h.Runs.ListDeliveries(c.Request.Context(), id, after, limit)
+	if err != nil {
+		respondError(c, h.logger, http.StatusInternalServerError, "Failed to list job deliveries", err)
+		return
+	}
+
+	resp := make([]JobDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, ToJobDeliveryResponse(d))
+	}
+	payload := gin.H{"job": ToJobRunResponse(run), "deliveries": resp}
+	if len(deliveries) == limit {
+		payload["next_after"] = deliveries[len(deliveries)-1].ID
+	}
+	respondSuccess(c, h.logger, http.StatusOK, payload)
+}
+
+func queryInt(c *gin.Context, key string, def int64) (int64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v < 0 {
+		return 0, errors.New(key + " must be a non-negative integer")
+	}
+	return v, nil
+}
+
+func queryLimit(c *gin.Context, def int) (int, error) {
+	limit, err := queryInt(c, "limit", int64(def))
+	if err != nil {
+		return 0, err
+	}
+	if limit == 0 || limit > maxListLimit {
+		return 0, errors.New("limit must be between 1 and 1000")
+	}
+	return int(limit), nil
+}
+
+func RegisterJobRoutes(admin *gin.RouterGroup, jobHandler *JobHandler) {
+	admin.GET("/jobs", jobHandler.ListJobs)
+	admin.GET("/jobs/:id", jobHandler.GetJob)
+}
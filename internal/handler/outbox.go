@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const defaultOutboxLimit = 50
+
+type OutboxAdmin interface {
+	ListMessages(ctx context.Context, status string, beforeID int64, limit int) ([]*model.OutboxMessage, error)
+	GetMessage(ctx context.Context, id int64) (*model.OutboxMessage, error)
+	Replay(ctx context.Context, id int64) (*model.OutboxMessage, error)
+}
+
+type OutboxHandler struct {
+	Outbox OutboxAdmin
+	logger *zap.Logger
+}
+
+func NewOutboxHandler(admin OutboxAdmin, logger *zap.Logger) *OutboxHandler {
+	return &OutboxHandler{Outbox: admin, logger: pkg.OrNop(logger)}
+}
+
+func (h *OutboxHandler) ListMessages(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", model.OutboxPending, model.OutboxSent, model.OutboxDead:
+	default:
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid status", errors.New("status must be pending, sent or dead"))
+		return
+	}
+	before, err := queryInt(c, "before", 0)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid before", err)
+		return
+	}
+	limit, err := queryLimit(c, defaultOutboxLimit)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	msgs, err := h.Outbox.ListMessages(c.Request.Context(), status, before, limit)
+	if err != nil {
+		respondError(c, h.logger, http.StatusInternalServerError, "Failed to list outbox messages", err)
+		return
+	}
+	resp := make([]OutboxMessageResponse, 0, len(msgs))
+	for _, m := range msgs {
+		resp = append(resp, ToOutboxMessageResponse(m))
+	}
+	payload := gin.H{"messages": resp}
+	if len(msgs) == limit {
+		payload["next_before"] = msgs[len(msgs)-1].ID
+	}
+	respondSuccess(c, h.logger, http.StatusOK, payload)
+}
+
+func (h *OutboxHandler) GetMessage(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+	msg, err := h.Outbox.GetMessage(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, h.logger, http.StatusNotFound, "Outbox message not found", err)
+		return
+	}
+	if err != nil {
+		respondError(c, h.logger, http.StatusInternalServerError, "Failed to get outbox message", err)
+		return
+	}
+	respondSuccess(c, h.logger, http.StatusOK, gin.H{"message": ToOutboxMessageResponse(msg)})
+}
+
+func (h *OutboxHandler) Replay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+	msg, err := h.Outbox.Replay(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, h.logger, http.StatusNotFound, "Outbox message not found", err)
+		return
+	case errors.Is(err, outbox.ErrNotReplayable):
+		respondError(c, h.logger, http.StatusConflict, "Outbox message is not dead", err)
+		return
+	case err != nil:
+		respondError(c, h.logger, http.StatusInternalServerError, "Failed to replay outbox message", err)
+		return
+	}
+	respondSuccess(c, h.logger, http.StatusOK, gin.H{"message": ToOutboxMessageResponse(msg)})
+}
+
+func RegisterOutboxRoutes(admin *gin.RouterGroup, outboxHandler *OutboxHandler) {
+	admin.GET("/outbox", outboxHandler.ListMessages)
+	admin.GET("/outbox/:id", outboxHandler.GetMessage)
+	admin.POST("/outbox/:id/replay", outboxHandler.Replay)
+}
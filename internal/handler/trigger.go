@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+)
+
+type MailJobTrigger interface {
+	Run(ctx context.Context, scope service.Scope, dryRun bool) (*scheduler.Summary, error)
+}
+
+type TriggerMailJobRequest struct {
+	City           string `json:"city"`
+	SubscriptionID int64  `json:"subscription_id"`
+	DryRun         bool   `json:"dry_run"`
+}
+
+type TriggerHandler struct {
+	Trigger MailJobTrigger
+	logger  *zap.Logger
+}
+
+func NewTriggerHandler(trigger MailJobTrigger, logger *zap.Logger) *TriggerHandler {
+	return &TriggerHandler{Trigger: trigger, logger: pkg.OrNop(logger)}
+}
+
+// RunMailJob runs the mail job right away and waits for it. An empty body runs
+// it for every due subscription.
+func (h *TriggerHandler) RunMailJob(c *gin.Context) {
+	var req TriggerMailJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	if req.SubscriptionID < 0 {
+		respondError(c, h.logger, http.StatusBadRequest, "Invalid subscription ID", errors.New("subscription_id must be positive"))
+		return
+	}
+
+	scope := service.Scope{City: req.City, SubscriptionID: req.SubscriptionID}
+	summary, err := h.Trigger.Run(c.Request.Context(), scope, req.DryRun)
+	if errors.Is(err, scheduler.ErrLocked) {
+		respondError(c, h.logger, http.StatusConflict, "Mail job is already running", err)
+		return
+	}
+	if err != nil {
+		respondError(c, h.logger, http.StatusInternalServerError, "Mail job failed", err)
+		return
+	}
+	respondSuccess(c, h.logger, http.StatusOK, gin.H{"summary": summary})
+}
+
+func RegisterTriggerRoutes(admin *gin.RouterGroup, triggerHandler *TriggerHandler) {
+	admin.POST("/jobs/mail_job/run", triggerHandler.RunMailJob)
+}
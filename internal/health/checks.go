@@ -0,0 +1,126 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func DatabaseCheck(db *gorm.DB) Check {
+	return func(ctx context.Context) Result {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return down(err, nil)
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return down(err, nil)
+		}
+		stats := sqlDB.Stats()
+		return up(map[string]any{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		})
+	}
+}
+
+func SMTPCheck(host, port string) Check {
+	return DialCheck(net.JoinHostPort(host, port))
+}
+
+// DialCheck reports whether a TCP connection to addr can be opened.
+func DialCheck(addr string) Check {
+	return func(ctx context.Context) Result {
+		var d net.Dialer
+		start := time.Now()
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return down(err, map[string]any{"addr": addr})
+		}
+		_ = conn.Close()
+		return up(map[string]any{"addr": addr, "dial_ms": time.Since(start).Milliseconds()})
+	}
+}
+
+// FailureCounter is implemented by clients that count how many of their calls
+// failed in a row. It only observes: calls keep going out however long the
+// streak gets.
+type FailureCounter interface {
+	// ConsecutiveFailures returns the number of calls that failed since the
+	// last successful one and a short reason for the latest failure. The
+	// reason ends up in the public readiness report, so it must not carry
+	// URLs or credentials.
+	ConsecutiveFailures() (int, string)
+	// LastCalls returns when a call last succeeded and last failed, zero if
+	// it never did.
+	LastCalls() (success, failure time.Time)
+}
+
+// WeatherProviderCheck reports the provider as degraded once it has failed
+// maxFailures times in a row. It never reports down: the API can still serve
+// subscriptions while the provider is unavailable.
+func WeatherProviderCheck(provider FailureCounter, maxFailures int) Check {
+	return func(ctx context.Context) Result {
+		failures, reason := provider.ConsecutiveFailures()
+		lastSuccess, lastFailure := provider.LastCalls()
+		details := map[string]any{
+			"last_success":         timeOrNil(lastSuccess),
+			"last_failure":         timeOrNil(lastFailure),
+			"consecutive_failures": failures,
+		}
+		if failures >= maxFailures {
+			return Result{Status: StatusDegraded, Error: reason, Details: details}
+		}
+		return up(details)
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+type JobStatus struct {
+	Running        bool
+	LastStartedAt  *time.Time
+	LastFinishedAt *time.Time
+	LastError      string
+}
+
+type JobStatusReporter interface {
+	Status() JobStatus
+}
+
+// SchedulerCheck reports the scheduler as degraded when the last run failed or
+// when no run has finished within maxAge of startedAt.
+func SchedulerCheck(job JobStatusReporter, startedAt time.Time, maxAge time.Duration) Check {
+	return func(ctx context.Context) Result {
+		st := job.Status()
+		details := map[string]any{
+			"running":          st.Running,
+			"last_started_at":  st.LastStartedAt,
+			"last_finished_at": st.LastFinishedAt,
+		}
+
+		lastSeen := startedAt
+		if st.LastFinishedAt != nil {
+			lastSeen = *st.LastFinishedAt
+		}
+		if time.Since(lastSeen) > maxAge && !st.Running {
+			return Result{
+				Status:  StatusDegraded,
+				Error:   fmt.Sprintf("no completed run since %s", lastSeen.Format(time.RFC3339)),
+				Details: details,
+			}
+		}
+		if st.LastError != "" {
+			return Result{Status: StatusDegraded, Error: st.LastError, Details: details}
+		}
+		return up(details)
+	}
+}
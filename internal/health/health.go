@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+type Result struct {
+	Status  Status         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type Check func(ctx context.Context) Result
+
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type registered struct {
+	name     string
+	critical bool
+	check    Check
+}
+
+// Registry runs named readiness checks. A failing critical check makes the
+// whole report "down"; a failing non-critical one only degrades it.
+type Registry struct {
+	timeout time.Duration
+	checks  []registered
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+func (r *Registry) Register(name string, critical bool, check Check) {
+	r.checks = append(r.checks, registered{name: name, critical: critical, check: check})
+}
+
+func (r *Registry) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	results := make([]Result, len(r.checks))
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func(i int, c registered) {
+			defer wg.Done()
+			results[i] = c.check(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(r.checks))}
+	for i, c := range r.checks {
+		res := results[i]
+		report.Checks[c.name] = res
+		switch {
+		case res.Status == StatusUp:
+		case c.critical && res.Status == StatusDown:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func up(details map[string]any) Result {
+	return Result{Status: StatusUp, Details: details}
+}
+
+func down(err error, details map[string]any) Result {
+	return Result{Status: StatusDown, Error: err.Error(), Details: details}
+}
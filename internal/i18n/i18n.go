@@ -0,0 +1,101 @@
+// Package i18n translates the text the service shows to subscribers. Messages
+// are identified by their English text, so English needs no catalog and a
+// message missing from a catalog falls back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	English   = "en"
+	Ukrainian = "uk"
+
+	Default = English
+)
+
+//go:embed locales/*.json
+var files embed.FS
+
+var catalogs = map[string]map[string]string{}
+
+func init() {
+	for _, lang := range []string{Ukrainian} {
+		raw, err := files.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(err)
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(raw, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: parse %s catalog: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+	}
+}
+
+// Supported reports whether lang is one of the languages the service speaks.
+func Supported(lang string) bool {
+	if lang == English {
+		return true
+	}
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Normalize maps a language tag such as "uk-UA" to a supported language,
+// falling back to Default.
+func Normalize(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	if Supported(primary) {
+		return primary
+	}
+	return Default
+}
+
+// FromAcceptLanguage picks the supported language the client prefers most
+// from an Accept-Language header, or Default if there is none.
+func FromAcceptLanguage(header string) string {
+	type choice struct {
+		lang string
+		q    float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if q <= 0 || !Supported(primary) {
+			continue
+		}
+		choices = append(choices, choice{lang: primary, q: q})
+	}
+	if len(choices) == 0 {
+		return Default
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].lang
+}
+
+// T translates msg into lang and formats it with args like fmt.Sprintf.
+func T(lang, msg string, args ...any) string {
+	if translated, ok := catalogs[lang][msg]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
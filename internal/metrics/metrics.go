@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "weather_app"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	WeatherProviderDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "weather_provider_request_duration_seconds",
+		Help:      "Latency of weather provider calls by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "outcome"})
+
+	WeatherProviderErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "weather_provider_errors_total",
+		Help:      "Failed weather provider calls by reason.",
+	}, []string{"provider", "reason"})
+
+	WeatherCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "weather_cache_requests_total",
+		Help:      "Weather cache lookups by result (hit or miss).",
+	}, []string{"result"})
+
+	EmailsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_sent_total",
+		Help:      "Emails handed to the mail transport by type.",
+	}, []string{"type"})
+
+	EmailsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_failed_total",
+		Help:      "Emails that failed to send by type.",
+	}, []string{"type"})
+
+	NotificationsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_sent_total",
+		Help:      "Weather updates delivered by channel.",
+	}, []string{"channel"})
+
+	NotificationsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_failed_total",
+		Help:      "Weather updates that failed to deliver by channel.",
+	}, []string{"channel"})
+
+	MailJobDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mail_job_duration_seconds",
+		Help:      "Duration of weather mail job runs.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600},
+	})
+
+	MailJobSubscribers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mail_job_subscribers_total",
+		Help:      "Subscribers handled by the mail job by outcome.",
+	}, []string{"outcome"})
+)
+
+const (
+	EmailTypeConfirmation  = "confirmation"
+	EmailTypeWeatherUpdate = "weather_update"
+	EmailTypeAlert         = "alert"
+	EmailTypeGoodbye       = "goodbye"
+
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+
+	OutcomeProcessed = "processed"
+	OutcomeSkipped   = "skipped"
+	OutcomeFailed    = "failed"
+)
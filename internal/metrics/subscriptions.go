@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultSubscriptionsTTL is how long subscription counts are reused between
+// scrapes.
+const DefaultSubscriptionsTTL = time.Minute
+
+type SubscriptionCounter interface {
+	CountSubscriptionsByState() (map[string]int64, error)
+}
+
+// SubscriptionsCollector reports subscription counts from the database rather
+// than in-process bookkeeping. Counts are cached for the TTL, so scraping the
+// public /metrics endpoint does not run a count per request.
+type SubscriptionsCollector struct {
+	counter SubscriptionCounter
+	ttl     time.Duration
+	desc    *prometheus.Desc
+	logger  *zap.Logger
+	Clock   clock.Clock
+
+	mu        sync.Mutex
+	counts    map[string]int64
+	fetchedAt time.Time
+}
+
+func NewSubscriptionsCollector(counter SubscriptionCounter, ttl time.Duration, logger *zap.Logger) *SubscriptionsCollector {
+	if ttl <= 0 {
+		ttl = DefaultSubscriptionsTTL
+	}
+	return &SubscriptionsCollector{
+		counter: counter,
+		ttl:     ttl,
+		logger:  pkg.OrNop(logger),
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "subscriptions"),
+			"Subscriptions by state.",
+			[]string{"state"}, nil,
+		),
+	}
+}
+
+func RegisterSubscriptionsCollector(counter SubscriptionCounter, ttl time.Duration, logger *zap.Logger) error {
+	return prometheus.Register(NewSubscriptionsCollector(counter, ttl, logger))
+}
+
+func (c *SubscriptionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *SubscriptionsCollector) Collect(ch chan<- prometheus.Metric) {
+	for state, n := range c.load() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), state)
+	}
+}
+
+// load returns the cached counts, refreshing them once they are older than
+// the TTL. A failed refresh keeps serving the previous counts.
+func (c *SubscriptionsCollector) load() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := clock.OrSystem(c.Clock).Now()
+	if c.counts != nil && now.Sub(c.fetchedAt) < c.ttl {
+		return c.counts
+	}
+	counts, err := c.counter.CountSubscriptionsByState()
+	if err != nil {
+		c.logger.Warn("failed to collect subscription counts", zap.Error(err))
+		return c.counts
+	}
+	c.counts = counts
+	c.fetchedAt = now
+	return counts
+}
@@ -2,24 +2,28 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+	model "github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Mailer is an autogenerated mock type for the Mailer type
 type Mailer struct {
 	mock.Mock
 }
 
-// SendConfirmation provides a mock function with given fields: email, token
-func (_m *Mailer) SendConfirmation(email string, token string) error {
-	ret := _m.Called(email, token)
+// SendConfirmation provides a mock function with given fields: ctx, email, token, lang
+func (_m *Mailer) SendConfirmation(ctx context.Context, email string, token string, lang string) error {
+	ret := _m.Called(ctx, email, token, lang)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendConfirmation")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(email, token)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, email, token, lang)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -27,17 +31,17 @@ func (_m *Mailer) SendConfirmation(email string, token string) error {
 	return r0
 }
 
-// SendWeatherUpdate provides a mock function with given fields: email, city, weatherInfo
-func (_m *Mailer) SendWeatherUpdate(email string, city string, weatherInfo string) error {
-	ret := _m.Called(email, city, weatherInfo)
+// SendWeatherUpdate provides a mock function with given fields: ctx, email, update
+func (_m *Mailer) SendWeatherUpdate(ctx context.Context, email string, update model.WeatherUpdate) error {
+	ret := _m.Called(ctx, email, update)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendWeatherUpdate")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(email, city, weatherInfo)
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.WeatherUpdate) error); ok {
+		r0 = rf(ctx, email, update)
 	} else {
 		r0 = ret.Error(0)
 	}
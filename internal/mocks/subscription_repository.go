@@ -3,7 +3,9 @@
 package mocks
 
 import (
+	context "context"
 	model "github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	service "github.com/l4ndm1nes/Weather-API-Application/internal/service"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -12,17 +14,65 @@ type SubscriptionRepository struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: sub
-func (_m *SubscriptionRepository) Create(sub *model.Subscription) error {
-	ret := _m.Called(sub)
+// ClaimDue provides a mock function with given fields: ctx, q
+func (_m *SubscriptionRepository) ClaimDue(ctx context.Context, q service.ClaimQuery) ([]*model.Subscription, error) {
+	ret := _m.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDue")
+	}
+
+	var r0 []*model.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, service.ClaimQuery) ([]*model.Subscription, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, service.ClaimQuery) []*model.Subscription); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, service.ClaimQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, sub
+func (_m *SubscriptionRepository) Create(ctx context.Context, sub *model.Subscription) error {
+	ret := _m.Called(ctx, sub)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.Subscription) error); ok {
-		r0 = rf(sub)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Subscription) error); ok {
+		r0 = rf(ctx, sub)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateWithOutbox provides a mock function with given fields: ctx, sub, msg
+func (_m *SubscriptionRepository) CreateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error {
+	ret := _m.Called(ctx, sub, msg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWithOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Subscription, *model.OutboxMessage) error); ok {
+		r0 = rf(ctx, sub, msg)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -30,9 +80,9 @@ func (_m *SubscriptionRepository) Create(sub *model.Subscription) error {
 	return r0
 }
 
-// FindByEmail provides a mock function with given fields: email
-func (_m *SubscriptionRepository) FindByEmail(email string) (*model.Subscription, error) {
-	ret := _m.Called(email)
+// FindByEmail provides a mock function with given fields: ctx, email
+func (_m *SubscriptionRepository) FindByEmail(ctx context.Context, email string) (*model.Subscription, error) {
+	ret := _m.Called(ctx, email)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByEmail")
@@ -40,19 +90,19 @@ func (_m *SubscriptionRepository) FindByEmail(email string) (*model.Subscription
 
 	var r0 *model.Subscription
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*model.Subscription, error)); ok {
-		return rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Subscription, error)); ok {
+		return rf(ctx, email)
 	}
-	if rf, ok := ret.Get(0).(func(string) *model.Subscription); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Subscription); ok {
+		r0 = rf(ctx, email)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Subscription)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -60,9 +110,9 @@ func (_m *SubscriptionRepository) FindByEmail(email string) (*model.Subscription
 	return r0, r1
 }
 
-// GetAllConfirmed provides a mock function with no fields
-func (_m *SubscriptionRepository) GetAllConfirmed() ([]*model.Subscription, error) {
-	ret := _m.Called()
+// GetAllConfirmed provides a mock function with given fields: ctx
+func (_m *SubscriptionRepository) GetAllConfirmed(ctx context.Context) ([]*model.Subscription, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllConfirmed")
@@ -70,19 +120,19 @@ func (_m *SubscriptionRepository) GetAllConfirmed() ([]*model.Subscription, erro
 
 	var r0 []*model.Subscription
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*model.Subscription, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.Subscription, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() []*model.Subscription); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Subscription); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.Subscription)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -90,9 +140,9 @@ func (_m *SubscriptionRepository) GetAllConfirmed() ([]*model.Subscription, erro
 	return r0, r1
 }
 
-// GetByToken provides a mock function with given fields: token
-func (_m *SubscriptionRepository) GetByToken(token string) (*model.Subscription, error) {
-	ret := _m.Called(token)
+// GetByToken provides a mock function with given fields: ctx, token
+func (_m *SubscriptionRepository) GetByToken(ctx context.Context, token string) (*model.Subscription, error) {
+	ret := _m.Called(ctx, token)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByToken")
@@ -100,19 +150,19 @@ func (_m *SubscriptionRepository) GetByToken(token string) (*model.Subscription,
 
 	var r0 *model.Subscription
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*model.Subscription, error)); ok {
-		return rf(token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Subscription, error)); ok {
+		return rf(ctx, token)
 	}
-	if rf, ok := ret.Get(0).(func(string) *model.Subscription); ok {
-		r0 = rf(token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Subscription); ok {
+		r0 = rf(ctx, token)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Subscription)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(token)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -120,17 +170,47 @@ func (_m *SubscriptionRepository) GetByToken(token string) (*model.Subscription,
 	return r0, r1
 }
 
-// UnsubscribeByToken provides a mock function with given fields: token
-func (_m *SubscriptionRepository) UnsubscribeByToken(token string) error {
-	ret := _m.Called(token)
+// ListDue provides a mock function with given fields: ctx, q
+func (_m *SubscriptionRepository) ListDue(ctx context.Context, q service.DueQuery) ([]*model.Subscription, error) {
+	ret := _m.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDue")
+	}
+
+	var r0 []*model.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, service.DueQuery) ([]*model.Subscription, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, service.DueQuery) []*model.Subscription); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, service.DueQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnsubscribeByToken provides a mock function with given fields: ctx, token
+func (_m *SubscriptionRepository) UnsubscribeByToken(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UnsubscribeByToken")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -138,17 +218,35 @@ func (_m *SubscriptionRepository) UnsubscribeByToken(token string) error {
 	return r0
 }
 
-// Update provides a mock function with given fields: sub
-func (_m *SubscriptionRepository) Update(sub *model.Subscription) error {
-	ret := _m.Called(sub)
+// Update provides a mock function with given fields: ctx, sub
+func (_m *SubscriptionRepository) Update(ctx context.Context, sub *model.Subscription) error {
+	ret := _m.Called(ctx, sub)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.Subscription) error); ok {
-		r0 = rf(sub)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Subscription) error); ok {
+		r0 = rf(ctx, sub)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateWithOutbox provides a mock function with given fields: ctx, sub, msg
+func (_m *SubscriptionRepository) UpdateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error {
+	ret := _m.Called(ctx, sub, msg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWithOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Subscription, *model.OutboxMessage) error); ok {
+		r0 = rf(ctx, sub, msg)
 	} else {
 		r0 = ret.Error(0)
 	}
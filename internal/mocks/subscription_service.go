@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	context "context"
 	model "github.com/l4ndm1nes/Weather-API-Application/internal/model"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,17 +13,17 @@ type SubscriptionService struct {
 	mock.Mock
 }
 
-// ConfirmSubscription provides a mock function with given fields: token
-func (_m *SubscriptionService) ConfirmSubscription(token string) error {
-	ret := _m.Called(token)
+// ConfirmSubscription provides a mock function with given fields: ctx, token
+func (_m *SubscriptionService) ConfirmSubscription(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ConfirmSubscription")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -30,9 +31,9 @@ func (_m *SubscriptionService) ConfirmSubscription(token string) error {
 	return r0
 }
 
-// Subscribe provides a mock function with given fields: sub
-func (_m *SubscriptionService) Subscribe(sub *model.Subscription) (*model.Subscription, error) {
-	ret := _m.Called(sub)
+// Subscribe provides a mock function with given fields: ctx, sub
+func (_m *SubscriptionService) Subscribe(ctx context.Context, sub *model.Subscription) (*model.Subscription, error) {
+	ret := _m.Called(ctx, sub)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Subscribe")
@@ -40,19 +41,19 @@ func (_m *SubscriptionService) Subscribe(sub *model.Subscription) (*model.Subscr
 
 	var r0 *model.Subscription
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*model.Subscription) (*model.Subscription, error)); ok {
-		return rf(sub)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Subscription) (*model.Subscription, error)); ok {
+		return rf(ctx, sub)
 	}
-	if rf, ok := ret.Get(0).(func(*model.Subscription) *model.Subscription); ok {
-		r0 = rf(sub)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Subscription) *model.Subscription); ok {
+		r0 = rf(ctx, sub)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Subscription)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*model.Subscription) error); ok {
-		r1 = rf(sub)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Subscription) error); ok {
+		r1 = rf(ctx, sub)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -60,17 +61,17 @@ func (_m *SubscriptionService) Subscribe(sub *model.Subscription) (*model.Subscr
 	return r0, r1
 }
 
-// Unsubscribe provides a mock function with given fields: token
-func (_m *SubscriptionService) Unsubscribe(token string) error {
-	ret := _m.Called(token)
+// Unsubscribe provides a mock function with given fields: ctx, token
+func (_m *SubscriptionService) Unsubscribe(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Unsubscribe")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(token)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	context "context"
 	model "github.com/l4ndm1nes/Weather-API-Application/internal/model"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,29 +13,29 @@ type WeatherService struct {
 	mock.Mock
 }
 
-// GetWeather provides a mock function with given fields: city
-func (_m *WeatherService) GetWeather(city string) (*model.Weather, error) {
-	ret := _m.Called(city)
+// GetLocalizedWeather provides a mock function with given fields: ctx, city, lang
+func (_m *WeatherService) GetLocalizedWeather(ctx context.Context, city string, lang string) (*model.Weather, error) {
+	ret := _m.Called(ctx, city, lang)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetWeather")
+		panic("no return value specified for GetLocalizedWeather")
 	}
 
 	var r0 *model.Weather
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*model.Weather, error)); ok {
-		return rf(city)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Weather, error)); ok {
+		return rf(ctx, city, lang)
 	}
-	if rf, ok := ret.Get(0).(func(string) *model.Weather); ok {
-		r0 = rf(city)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Weather); ok {
+		r0 = rf(ctx, city, lang)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Weather)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(city)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, city, lang)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,23 @@
+package model
+
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+	ChannelSlack    = "slack"
+	ChannelDiscord  = "discord"
+	ChannelWebhook  = "webhook"
+)
+
+// Target returns the channel and destination weather updates go to. Email
+// subscriptions always get their updates at Email, the address that was
+// confirmed, whatever Destination holds.
+func (s *Subscription) Target() (channel, destination string) {
+	channel, destination = s.Channel, s.Destination
+	if channel == "" {
+		channel = ChannelEmail
+	}
+	if channel == ChannelEmail {
+		destination = s.Email
+	}
+	return channel, destination
+}
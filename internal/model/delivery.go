@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Delivery is the ledger entry for the one weather update a subscription may
+// receive in a scheduled period.
+type Delivery struct {
+	ID             int64
+	SubscriptionID int64
+	Period         time.Time
+	Status         string
+	Attempts       int
+	LastError      string
+	SentAt         *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
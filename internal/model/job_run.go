@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+const (
+	JobRunRunning     = "running"
+	JobRunSucceeded   = "succeeded"
+	JobRunFailed      = "failed"
+	JobRunInterrupted = "interrupted"
+
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliverySkipped = "skipped"
+	DeliveryFailed  = "failed"
+)
+
+type JobRun struct {
+	ID         int64
+	Name       string
+	WorkerID   string
+	Status     string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Total      int
+	Sent       int
+	Skipped    int
+	Failed     int
+	Error      string
+}
+
+type JobDelivery struct {
+	ID             int64
+	RunID          int64
+	SubscriptionID int64
+	City           string
+	Status         string
+	Reason         string
+	Error          string
+	CreatedAt      time.Time
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	OutboxConfirmation  = "confirmation"
+	OutboxWeatherUpdate = "weather_update"
+
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
+// OutboxMessage is an email waiting to be sent by the outbox dispatcher.
+type OutboxMessage struct {
+	ID            int64
+	Kind          string
+	Recipient     string
+	Payload       OutboxPayload
+	DeliveryID    *int64
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// OutboxPayload holds what the mailer needs to render the email. Token is the
+// confirm token of a confirmation and the unsubscribe token of a weather
+// update. Weather updates go to Destination over Channel, or to the recipient
+// by email when Channel is empty. Secret signs webhook deliveries.
+type OutboxPayload struct {
+	Token       string   `json:"token,omitempty"`
+	City        string   `json:"city,omitempty"`
+	Weather     *Weather `json:"weather,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Channel     string   `json:"channel,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+}
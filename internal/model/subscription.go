@@ -10,7 +10,19 @@ type Subscription struct {
 	Confirmed        bool
 	ConfirmToken     string
 	UnsubscribeToken string
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	LastSentAt       *time.Time
+	// Language is the i18n language of the emails sent to the subscriber.
+	Language string
+	// Channel and Destination say where weather updates go; see Target.
+	// Confirmation emails always go to Email.
+	Channel     string
+	Destination string
+	// WebhookSecret signs the deliveries of webhook subscriptions.
+	WebhookSecret string
+	// UndeliverableAt is set when the mail server permanently rejected Email;
+	// the mail job skips the subscription from then on.
+	UndeliverableAt     *time.Time
+	UndeliverableReason string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	LastSentAt          *time.Time
 }
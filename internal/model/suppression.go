@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	EmailEventBounce    = "bounce"
+	EmailEventComplaint = "complaint"
+)
+
+// EmailEvent is a bounce or spam complaint the email provider reported for
+// Email. Soft bounces are temporary and do not suppress the address.
+type EmailEvent struct {
+	Type   string
+	Email  string
+	Soft   bool
+	Detail string
+}
+
+// Suppression is an address that must never be emailed again. Reason is the
+// event type that put it on the list and Source the provider that reported it.
+type Suppression struct {
+	ID        int64
+	Email     string
+	Reason    string
+	Detail    string
+	Source    string
+	CreatedAt time.Time
+}
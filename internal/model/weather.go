@@ -5,3 +5,11 @@ type Weather struct {
 	Humidity    int
 	Description string
 }
+
+// WeatherUpdate is what a weather email reports to one subscriber.
+type WeatherUpdate struct {
+	City             string
+	Weather          Weather
+	UnsubscribeToken string
+	Language         string
+}
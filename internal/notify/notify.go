@@ -0,0 +1,179 @@
+// Package notify delivers weather updates over the channel a subscription
+// chose: email or one of the chat and webhook adapters registered in a
+// Registry.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/notify")
+
+var (
+	ErrUnknownChannel     = errors.New("unknown notification channel")
+	ErrInvalidDestination = errors.New("invalid notification destination")
+	ErrVerificationFailed = errors.New("notification destination verification failed")
+)
+
+// Target says where one subscription's weather updates go. Secret is only set
+// for channels that sign their deliveries.
+type Target struct {
+	Channel     string
+	Destination string
+	Secret      string
+}
+
+// Message is a weather update rendered for a channel. Text is the plain-text
+// body of the weather email, so every channel says the same thing in the
+// subscriber's language.
+type Message struct {
+	Update         model.WeatherUpdate
+	Subject        string
+	Text           string
+	UnsubscribeURL string
+	Secret         string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, destination string, msg Message) error
+}
+
+// DestinationValidator is implemented by notifiers that can reject a
+// destination at subscribe time instead of on the first delivery.
+type DestinationValidator interface {
+	ValidateDestination(destination string) error
+}
+
+// DestinationVerifier is implemented by notifiers that check, when the
+// subscription is confirmed, that whoever runs the destination agreed to
+// receive updates signed with secret.
+type DestinationVerifier interface {
+	VerifyDestination(ctx context.Context, destination, secret string) error
+}
+
+// Registry maps channel names to notifiers.
+type Registry struct {
+	baseURL   string
+	notifiers map[string]Notifier
+}
+
+// NewRegistry returns an empty registry. baseURL prefixes the unsubscribe
+// links in rendered messages.
+func NewRegistry(baseURL string) *Registry {
+	return &Registry{baseURL: baseURL, notifiers: map[string]Notifier{}}
+}
+
+func (r *Registry) Register(channel string, n Notifier) {
+	r.notifiers[channel] = n
+}
+
+func (r *Registry) Has(channel string) bool {
+	_, ok := r.notifiers[channel]
+	return ok
+}
+
+// Validate checks that channel is registered and, when its notifier can tell,
+// that destination is one it can deliver to.
+func (r *Registry) Validate(channel, destination string) error {
+	n, ok := r.notifiers[channel]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownChannel, channel)
+	}
+	if v, ok := n.(DestinationValidator); ok {
+		if err := v.ValidateDestination(destination); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+		}
+	}
+	return nil
+}
+
+// Verify runs the handshake of the notifier registered for channel, if it has
+// one.
+func (r *Registry) Verify(ctx context.Context, target Target) error {
+	ctx, span := tracer.Start(ctx, "Registry.Verify")
+	defer span.End()
+	span.SetAttributes(attribute.String("notify.channel", target.Channel))
+
+	n, ok := r.notifiers[target.Channel]
+	if !ok {
+		return tracing.Error(span, fmt.Errorf("%w: %q", ErrUnknownChannel, target.Channel))
+	}
+	v, ok := n.(DestinationVerifier)
+	if !ok {
+		return nil
+	}
+	if err := v.VerifyDestination(ctx, target.Destination, target.Secret); err != nil {
+		return tracing.Error(span, fmt.Errorf("%w: %v", ErrVerificationFailed, err))
+	}
+	return nil
+}
+
+// Notify renders update and hands it to the notifier registered for the
+// target's channel.
+func (r *Registry) Notify(ctx context.Context, target Target, update model.WeatherUpdate) error {
+	ctx, span := tracer.Start(ctx, "Registry.Notify")
+	defer span.End()
+	channel := target.Channel
+	span.SetAttributes(attribute.String("notify.channel", channel))
+
+	n, ok := r.notifiers[channel]
+	if !ok {
+		return tracing.Error(span, fmt.Errorf("%w: %q", ErrUnknownChannel, channel))
+	}
+	email, err := templates.Render(update.Language, templates.WeatherUpdate, templates.WeatherUpdateData{
+		BaseURL:          r.baseURL,
+		City:             update.City,
+		Weather:          update.Weather,
+		UnsubscribeToken: update.UnsubscribeToken,
+	})
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+	msg := Message{
+		Update:         update,
+		Subject:        email.Subject,
+		Text:           email.Text,
+		UnsubscribeURL: r.baseURL + "/api/unsubscribe/" + update.UnsubscribeToken,
+		Secret:         target.Secret,
+	}
+	if err := n.Notify(ctx, target.Destination, msg); err != nil {
+		metrics.NotificationsFailed.WithLabelValues(channel).Inc()
+		return tracing.Error(span, err)
+	}
+	metrics.NotificationsSent.WithLabelValues(channel).Inc()
+	return nil
+}
+
+type WeatherMailer interface {
+	SendWeatherUpdate(ctx context.Context, email string, update model.WeatherUpdate) error
+}
+
+// MailNotifier sends weather updates as email. The mailer renders its own
+// multipart message, so only msg.Update is used.
+type MailNotifier struct {
+	Mailer WeatherMailer
+}
+
+func (m MailNotifier) Notify(ctx context.Context, destination string, msg Message) error {
+	return m.Mailer.SendWeatherUpdate(ctx, destination, msg.Update)
+}
+
+// ValidateDestination accepts an empty destination, which means the
+// subscription's own email address. The service also requires a non-empty one
+// to be that address, since only it is confirmed.
+func (m MailNotifier) ValidateDestination(destination string) error {
+	if destination == "" {
+		return nil
+	}
+	_, err := mail.ParseAddress(destination)
+	return err
+}
@@ -0,0 +1,309 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/outbox")
+
+var ErrNotReplayable = errors.New("only dead outbox messages can be replayed")
+
+type Store interface {
+	// ClaimDue returns pending messages whose next attempt is due and pushes
+	// their next attempt out by lease, so other dispatchers skip them while
+	// this one is sending.
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.OutboxMessage, error)
+	MarkSent(ctx context.Context, id int64, attempts int, at time.Time) error
+	MarkRetry(ctx context.Context, id int64, attempts int, next time.Time, lastErr string) error
+	MarkDead(ctx context.Context, id int64, attempts int, lastErr string) error
+}
+
+// Recipients flags subscriptions whose email address the mail server
+// permanently rejected.
+type Recipients interface {
+	MarkUndeliverable(ctx context.Context, email, reason string, at time.Time) error
+}
+
+// Suppressions tells whether an address must not be emailed.
+type Suppressions interface {
+	IsSuppressed(ctx context.Context, email string) (bool, error)
+}
+
+// Ledger settles the delivery ledger entry a weather update was queued for.
+type Ledger interface {
+	MarkSent(ctx context.Context, id int64, at time.Time) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Lease        time.Duration
+	// Clock defaults to the wall clock.
+	Clock clock.Clock
+	// Recipients, when set, is told about addresses that were rejected
+	// permanently.
+	Recipients Recipients
+	// Suppressions, when set, dead-letters emails to suppressed addresses
+	// that were queued before the address was suppressed.
+	Suppressions Suppressions
+	// Notifiers delivers weather updates over their subscription's channel.
+	// It defaults to a registry that only knows email, sent via the mailer.
+	Notifiers *notify.Registry
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 5 * time.Second,
+		BatchSize:    50,
+		MaxAttempts:  8,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   time.Hour,
+		Lease:        5 * time.Minute,
+	}
+}
+
+type Dispatcher struct {
+	store  Store
+	mailer service.Mailer
+	ledger Ledger
+	cfg    Config
+	logger *zap.Logger
+}
+
+// NewDispatcher fills zero fields of cfg from DefaultConfig.
+func NewDispatcher(store Store, mailer service.Mailer, ledger Ledger, cfg Config, logger *zap.Logger) *Dispatcher {
+	def := DefaultConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = def.PollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = def.BaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = def.MaxBackoff
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = def.Lease
+	}
+	cfg.Clock = clock.OrSystem(cfg.Clock)
+	if cfg.Notifiers == nil {
+		cfg.Notifiers = notify.NewRegistry("")
+		cfg.Notifiers.Register(model.ChannelEmail, notify.MailNotifier{Mailer: mailer})
+	}
+	return &Dispatcher{store: store, mailer: mailer, ledger: ledger, cfg: cfg, logger: pkg.OrNop(logger)}
+}
+
+// Run dispatches due messages every PollInterval until ctx is cancelled. A
+// message that is being sent when ctx is cancelled is finished first.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := d.DispatchOnce(ctx)
+			if err != nil {
+				d.logger.Error("outbox dispatch failed", zap.Error(err))
+			}
+			if err != nil || n < d.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due messages and returns how many were
+// claimed.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "Dispatcher.DispatchOnce")
+	defer span.End()
+
+	msgs, err := d.store.ClaimDue(ctx, d.cfg.Clock.Now(), d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, tracing.Error(span, fmt.Errorf("failed to claim outbox messages: %w", err))
+	}
+	span.SetAttributes(attribute.Int("outbox.claimed", len(msgs)))
+
+	sendCtx := context.WithoutCancel(ctx)
+	for i, msg := range msgs {
+		if ctx.Err() != nil {
+			// The remaining claims expire after the lease and are picked up again.
+			d.logger.Info("outbox dispatch interrupted", zap.Int("remaining", len(msgs)-i))
+			break
+		}
+		d.dispatch(sendCtx, msg)
+	}
+	return len(msgs), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, msg *model.OutboxMessage) {
+	ctx, span := tracer.Start(ctx, "Dispatcher.dispatch")
+	defer span.End()
+	span.SetAttributes(
+		attribute.Int64("outbox.id", msg.ID),
+		attribute.String("outbox.kind", msg.Kind),
+		attribute.Int("outbox.attempt", msg.Attempts+1),
+	)
+
+	attempts := msg.Attempts + 1
+	sendErr := d.checkSuppressed(ctx, msg)
+	if sendErr == nil {
+		sendErr = d.send(ctx, msg)
+	}
+	if sendErr == nil {
+		now := d.cfg.Clock.Now()
+		if err := d.store.MarkSent(ctx, msg.ID, attempts, now); err != nil {
+			d.logger.Error("failed to mark outbox message sent", zap.Int64("outbox_id", msg.ID), zap.Error(err))
+		}
+		if msg.DeliveryID != nil && d.ledger != nil {
+			if err := d.ledger.MarkSent(ctx, *msg.DeliveryID, now); err != nil {
+				d.logger.Warn("failed to mark delivery sent", zap.Int64("delivery_id", *msg.DeliveryID), zap.Error(err))
+			}
+		}
+		return
+	}
+	_ = tracing.Error(span, sendErr)
+
+	permanent := isPermanent(sendErr)
+	if permanent && isRecipientRejected(sendErr) && d.cfg.Recipients != nil {
+		if err := d.cfg.Recipients.MarkUndeliverable(ctx, msg.Recipient, sendErr.Error(), d.cfg.Clock.Now()); err != nil {
+			d.logger.Warn("failed to mark recipient undeliverable", zap.Int64("outbox_id", msg.ID), zap.Error(err))
+		}
+	}
+
+	if permanent || attempts >= d.cfg.MaxAttempts {
+		d.logger.Error("outbox message dead-lettered",
+			zap.Int64("outbox_id", msg.ID),
+			zap.String("kind", msg.Kind),
+			zap.Int("attempts", attempts),
+			zap.Bool("permanent", permanent),
+			zap.Error(sendErr),
+		)
+		if err := d.store.MarkDead(ctx, msg.ID, attempts, sendErr.Error()); err != nil {
+			d.logger.Error("failed to dead-letter outbox message", zap.Int64("outbox_id", msg.ID), zap.Error(err))
+		}
+		if msg.DeliveryID != nil && d.ledger != nil {
+			if err := d.ledger.MarkFailed(ctx, *msg.DeliveryID, sendErr.Error()); err != nil {
+				d.logger.Warn("failed to mark delivery failed", zap.Int64("delivery_id", *msg.DeliveryID), zap.Error(err))
+			}
+		}
+		return
+	}
+
+	next := d.cfg.Clock.Now().Add(Backoff(attempts, d.cfg.BaseBackoff, d.cfg.MaxBackoff))
+	d.logger.Warn("outbox send failed, will retry",
+		zap.Int64("outbox_id", msg.ID),
+		zap.String("kind", msg.Kind),
+		zap.Int("attempts", attempts),
+		zap.Time("next_attempt_at", next),
+		zap.Error(sendErr),
+	)
+	if err := d.store.MarkRetry(ctx, msg.ID, attempts, next, sendErr.Error()); err != nil {
+		d.logger.Error("failed to reschedule outbox message", zap.Int64("outbox_id", msg.ID), zap.Error(err))
+	}
+}
+
+// errSuppressed is permanent, so the message is dead-lettered without a send.
+type errSuppressed struct{ email string }
+
+func (e errSuppressed) Error() string   { return fmt.Sprintf("%s is on the suppression list", e.email) }
+func (e errSuppressed) Permanent() bool { return true }
+
+// checkSuppressed fails messages that would email a suppressed address. A
+// failed lookup is retried like a failed send.
+func (d *Dispatcher) checkSuppressed(ctx context.Context, msg *model.OutboxMessage) error {
+	if d.cfg.Suppressions == nil {
+		return nil
+	}
+	email := msg.Recipient
+	if msg.Kind == model.OutboxWeatherUpdate && msg.Payload.Channel != "" {
+		if msg.Payload.Channel != model.ChannelEmail {
+			return nil
+		}
+		email = msg.Payload.Destination
+	}
+	suppressed, err := d.cfg.Suppressions.IsSuppressed(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	if suppressed {
+		return errSuppressed{email: email}
+	}
+	return nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, msg *model.OutboxMessage) error {
+	switch msg.Kind {
+	case model.OutboxConfirmation:
+		return d.mailer.SendConfirmation(ctx, msg.Recipient, msg.Payload.Token, msg.Payload.Language)
+	case model.OutboxWeatherUpdate:
+		if msg.Payload.Weather == nil {
+			return fmt.Errorf("weather update %d has no weather", msg.ID)
+		}
+		target := notify.Target{Channel: msg.Payload.Channel, Destination: msg.Payload.Destination, Secret: msg.Payload.Secret}
+		// Messages queued before channels existed have neither field set.
+		if target.Channel == "" {
+			target.Channel, target.Destination = model.ChannelEmail, msg.Recipient
+		}
+		return d.cfg.Notifiers.Notify(ctx, target, model.WeatherUpdate{
+			City:             msg.Payload.City,
+			Weather:          *msg.Payload.Weather,
+			UnsubscribeToken: msg.Payload.Token,
+			Language:         msg.Payload.Language,
+		})
+	default:
+		return fmt.Errorf("unknown outbox message kind %q", msg.Kind)
+	}
+}
+
+// isPermanent reports whether err says that resending the message cannot
+// succeed, like a 5xx SMTP reply. Anything else is retried with backoff.
+func isPermanent(err error) bool {
+	var p interface{ Permanent() bool }
+	return errors.As(err, &p) && p.Permanent()
+}
+
+// isRecipientRejected reports whether err blames the recipient's mailbox.
+func isRecipientRejected(err error) bool {
+	var r interface{ RecipientRejected() bool }
+	return errors.As(err, &r) && r.RecipientRejected()
+}
+
+// Backoff returns the delay before the attempt that follows the given failed
+// attempt: base, 2*base, 4*base, ... capped at max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return min(delay, max)
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/robfig/cron/v3"
+)
+
+// RunLog reads back recorded runs of a job. RunsSince returns the runs that
+// started at or after since, oldest first.
+type RunLog interface {
+	RunsSince(ctx context.Context, name string, since time.Time) ([]*model.JobRun, error)
+}
+
+// MailJobSpec is the cron spec of the hourly mail job, in the server's local
+// time.
+const MailJobSpec = "0 * * * *"
+
+// CatchUpPolicy decides which ticks of a job count as missed.
+type CatchUpPolicy struct {
+	// Schedule is the job's cron schedule; the ticks are its firing times.
+	Schedule cron.Schedule
+	// Grace is how long after a tick it is still made up. Zero disables
+	// catch-up.
+	Grace time.Duration
+	// Lease is how long a run may stay running before it is taken for
+	// crashed: a process that dies mid-run leaves its row running for good.
+	// Defaults to DefaultClaimLease.
+	Lease time.Duration
+}
+
+// MissedTicks returns, oldest first, every tick of p.Schedule in the last
+// p.Grace before now that went without a run. A tick counts as served when a
+// run started at or after it and succeeded or is still running within
+// p.Lease; one run serves every tick before it because each run picks up all
+// overdue subscriptions. Ticks older than the grace are left to the next
+// regular run.
+func MissedTicks(runs []*model.JobRun, now time.Time, p CatchUpPolicy) []time.Time {
+	if p.Grace <= 0 {
+		return nil
+	}
+	lease := p.Lease
+	if lease <= 0 {
+		lease = DefaultClaimLease
+	}
+	var served time.Time
+	for _, run := range runs {
+		switch {
+		case run.Status == model.JobRunSucceeded:
+		case run.Status == model.JobRunRunning && now.Sub(run.StartedAt) <= lease:
+		default:
+			continue
+		}
+		if run.StartedAt.After(served) {
+			served = run.StartedAt
+		}
+	}
+	var missed []time.Time
+	for tick := p.Schedule.Next(now.Add(-p.Grace - time.Second)); !tick.IsZero() && !tick.After(now); tick = p.Schedule.Next(tick) {
+		if served.IsZero() || served.Before(tick) {
+			missed = append(missed, tick)
+		}
+	}
+	return missed
+}
+
+// CatchUp loads the mail job runs recorded within the grace of now and returns
+// the ticks that were missed, for example because the service was down or
+// crashed mid-run, another instance held the lock or the previous run was
+// still going.
+func CatchUp(ctx context.Context, runs RunLog, now time.Time, p CatchUpPolicy) ([]time.Time, error) {
+	if p.Grace <= 0 {
+		return nil, nil
+	}
+	recorded, err := runs.RunsSince(ctx, MailJobName, now.Add(-p.Grace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s runs: %w", MailJobName, err)
+	}
+	return MissedTicks(recorded, now, p), nil
+}
+
+// overdue reports whether sub went without an email for the whole period
+// before the one now falls in, i.e. a run it was due in was missed.
+func overdue(sub *model.Subscription, now time.Time) bool {
+	if sub.LastSentAt == nil {
+		return false
+	}
+	previous := Period(sub.Frequency, Period(sub.Frequency, now).Add(-time.Second))
+	return sub.LastSentAt.Before(previous)
+}
+
+// Once is a cron schedule that fires a single time, at at or as soon as the
+// scheduler starts if at has already passed. Running a one-off job through
+// cron means shutdown waits for it like for any scheduled run.
+func Once(at time.Time) cron.Schedule {
+	return &onceSchedule{at: at}
+}
+
+type onceSchedule struct {
+	mu    sync.Mutex
+	at    time.Time
+	fired bool
+}
+
+func (s *onceSchedule) Next(t time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fired {
+		return time.Time{}
+	}
+	s.fired = true
+	if s.at.Before(t) {
+		return t
+	}
+	return s.at
+}
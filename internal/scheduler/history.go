@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+	"go.uber.org/zap"
+)
+
+// History persists job runs and the outcome for every subscription a run
+// touched.
+type History interface {
+	StartRun(ctx context.Context, run *model.JobRun) error
+	FinishRun(ctx context.Context, run *model.JobRun) error
+	AddDeliveries(ctx context.Context, deliveries []*model.JobDelivery) error
+}
+
+// runState collects the outcomes of one run. Outcomes are counted in the
+// summary straight away and buffered for History until the next flush.
+type runState struct {
+	mu      sync.Mutex
+	summary *Summary
+	run     *model.JobRun
+	history History
+	pending []*model.JobDelivery
+	clock   clock.Clock
+	logger  *zap.Logger
+}
+
+func newRunState(name, workerID string, history History, clk clock.Clock, logger *zap.Logger) *runState {
+	return &runState{
+		summary: newSummary(),
+		run: &model.JobRun{
+			Name:      name,
+			WorkerID:  workerID,
+			Status:    model.JobRunRunning,
+			StartedAt: clk.Now(),
+		},
+		history: history,
+		clock:   clk,
+		logger:  logger,
+	}
+}
+
+// start records the run. History failures are logged and otherwise ignored so
+// a broken history table never stops emails from going out.
+func (r *runState) start(ctx context.Context) {
+	if r.history == nil {
+		return
+	}
+	if err := r.history.StartRun(ctx, r.run); err != nil {
+		r.logger.Warn("failed to record job run start", zap.Error(err))
+		r.history = nil
+		return
+	}
+	r.summary.RunID = r.run.ID
+}
+
+func (r *runState) sent(sub *model.Subscription, caughtUp bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary.Sent++
+	if caughtUp {
+		r.summary.CaughtUp++
+	}
+	r.add(sub, model.DeliverySent, "", nil)
+}
+
+func (r *runState) preview(sub *model.Subscription, email *templates.Email, caughtUp bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary.Sent++
+	if caughtUp {
+		r.summary.CaughtUp++
+	}
+	channel, _ := sub.Target()
+	r.summary.Previews = append(r.summary.Previews, Preview{
+		SubscriptionID: sub.ID,
+		Email:          sub.Email,
+		Channel:        channel,
+		City:           sub.City,
+		Frequency:      sub.Frequency,
+		Subject:        email.Subject,
+		Body:           email.Text,
+		HTML:           email.HTML,
+	})
+}
+
+func (r *runState) skipped(sub *model.Subscription, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary.Skipped++
+	r.summary.SkippedByReason[reason]++
+	r.add(sub, model.DeliverySkipped, reason, nil)
+}
+
+func (r *runState) failed(sub *model.Subscription, reason string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary.Failed++
+	r.summary.FailedByReason[reason]++
+	r.add(sub, model.DeliveryFailed, reason, err)
+}
+
+func (r *runState) add(sub *model.Subscription, status, reason string, err error) {
+	if r.history == nil {
+		return
+	}
+	d := &model.JobDelivery{
+		RunID:          r.run.ID,
+		SubscriptionID: sub.ID,
+		City:           sub.City,
+		Status:         status,
+		Reason:         reason,
+	}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	r.pending = append(r.pending, d)
+}
+
+func (r *runState) flush(ctx context.Context) {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	if r.history == nil || len(pending) == 0 {
+		return
+	}
+	if err := r.history.AddDeliveries(ctx, pending); err != nil {
+		r.logger.Warn("failed to record job deliveries", zap.Int("count", len(pending)), zap.Error(err))
+	}
+}
+
+func (r *runState) finish(ctx context.Context, status string, err error) {
+	r.flush(ctx)
+	if r.history == nil {
+		return
+	}
+	now := r.clock.Now()
+	r.run.Status = status
+	r.run.FinishedAt = &now
+	r.run.Total = r.summary.Total
+	r.run.Sent = r.summary.Sent
+	r.run.Skipped = r.summary.Skipped
+	r.run.Failed = r.summary.Failed
+	if err != nil {
+		r.run.Error = err.Error()
+	}
+	if err := r.history.FinishRun(ctx, r.run); err != nil {
+		r.logger.Warn("failed to record job run finish", zap.Int64("run_id", r.run.ID), zap.Error(err))
+	}
+}
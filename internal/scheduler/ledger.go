@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"go.uber.org/zap"
+)
+
+// Ledger records which scheduled period each subscription has been emailed
+// for. An entry is reserved before the email is queued, so a crash can never
+// lead to a second email for the same period.
+type Ledger interface {
+	Reserve(ctx context.Context, subscriptionID int64, period time.Time) (*model.Delivery, bool, error)
+	MarkSent(ctx context.Context, id int64, at time.Time) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+}
+
+// Period returns the start of the scheduled period that t falls in for the
+// given frequency, in UTC.
+func Period(frequency string, t time.Time) time.Time {
+	t = t.UTC()
+	if frequency == "daily" {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// reserve takes the ledger entry for sub in the current period. When it
+// returns ok=false the subscriber has already been counted and must not be
+// emailed.
+func (j *mailJob) reserve(ctx context.Context, sub *model.Subscription) (*model.Delivery, string, bool) {
+	if j.opts.Ledger == nil {
+		return nil, "", true
+	}
+	entry, reserved, err := j.opts.Ledger.Reserve(ctx, sub.ID, Period(sub.Frequency, j.now))
+	if err != nil {
+		j.logger.Warn("failed to reserve delivery", zap.Int64("subscription_id", sub.ID), zap.Error(err))
+		j.state.failed(sub, ReasonLedgerUnavailable, err)
+		return nil, metrics.OutcomeFailed, false
+	}
+	if !reserved {
+		j.state.skipped(sub, ReasonAlreadyDelivered)
+		return nil, metrics.OutcomeSkipped, false
+	}
+	return entry, "", true
+}
+
+// markFailed only logs on error. An entry left pending is never retried, which
+// keeps the at-most-once guarantee.
+func (j *mailJob) markFailed(ctx context.Context, entry *model.Delivery, cause error) {
+	if entry == nil {
+		return
+	}
+	if err := j.opts.Ledger.MarkFailed(ctx, entry.ID, cause.Error()); err != nil {
+		j.logger.Warn("failed to mark delivery failed", zap.Int64("delivery_id", entry.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrLocked = errors.New("job is already running on another instance")
+
+// Locker grants cluster-wide exclusive locks by name.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
+// RunLocked runs job only if the named lock could be taken, and returns
+// ErrLocked otherwise. A nil locker runs the job unconditionally.
+func RunLocked(ctx context.Context, locker Locker, name string, job func() error) error {
+	if locker == nil {
+		return job()
+	}
+	unlock, acquired, err := locker.TryLock(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to acquire %s lock: %w", name, err)
+	}
+	if !acquired {
+		return ErrLocked
+	}
+	defer unlock()
+	return job()
+}
+
+// DefaultWorkerID identifies this process in row claims.
+func DefaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
@@ -1,51 +1,377 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
-	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
-func MailJob(subService *service.SubscriptionService, weatherService *service.WeatherService) error {
-	subs, err := subService.GetAllConfirmed()
-	if err != nil {
-		pkg.Logger.Error("failed to get confirmed subscriptions", zap.Error(err))
-		return fmt.Errorf("failed to get confirmed subscriptions: %w", err)
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/scheduler")
+
+var ErrInterrupted = errors.New("mail job interrupted")
+
+const (
+	DefaultConcurrency = 4
+	DefaultBatchSize   = 500
+	DefaultClaimLease  = 15 * time.Minute
+	MailJobName        = "mail_job"
+	ManualMailJobName  = "mail_job_manual"
+	MailJobLockName    = MailJobName
+)
+
+type Options struct {
+	// Concurrency bounds both the weather lookups and the email deliveries
+	// running at the same time.
+	Concurrency int
+	// BatchSize is the number of due subscriptions claimed per page.
+	BatchSize int
+	// WorkerID and ClaimLease identify and bound the claims this run takes on
+	// subscriptions, so several instances can share a run without sending
+	// the same email twice.
+	WorkerID   string
+	ClaimLease time.Duration
+	// History, when set, records the run and every subscription outcome.
+	History History
+	// Ledger, when set, guarantees at most one email per subscription and
+	// scheduled period.
+	Ledger Ledger
+	// Name is recorded in the job history. It defaults to MailJobName.
+	Name string
+	// Scope limits the run to one city or one subscription.
+	Scope service.Scope
+	// DryRun lists the due subscriptions and renders their emails into
+	// Summary.Previews without claiming rows, reserving ledger entries,
+	// queueing emails, updating LastSentAt or recording history.
+	DryRun bool
+	// BaseURL prefixes the unsubscribe link in dry run previews.
+	BaseURL string
+	// Clock decides which subscriptions are due and which period they are
+	// emailed for. It defaults to the wall clock.
+	Clock clock.Clock
+	// Channels, when set, fails subscriptions whose channel has no notifier
+	// instead of queueing updates the dispatcher cannot deliver.
+	Channels Channels
+}
+
+type Channels interface {
+	Has(channel string) bool
+}
+
+type delivery struct {
+	sub     *model.Subscription
+	weather *model.Weather
+}
+
+type lookup struct {
+	weather *model.Weather
+	err     error
+}
+
+type mailJob struct {
+	subService     *service.SubscriptionService
+	weatherService *service.WeatherService
+	opts           Options
+	state          *runState
+	now            time.Time
+	logger         *zap.Logger
+}
+
+func MailJob(
+	ctx context.Context,
+	subService *service.SubscriptionService,
+	weatherService *service.WeatherService,
+	opts Options,
+	logger *zap.Logger,
+) (*Summary, error) {
+	logger = pkg.FromContext(ctx, logger)
+
+	ctx, span := tracer.Start(ctx, "MailJob")
+	defer span.End()
+
+	start := time.Now()
+	defer func() {
+		if !opts.DryRun {
+			metrics.MailJobDuration.Observe(time.Since(start).Seconds())
+		}
+	}()
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.WorkerID == "" {
+		opts.WorkerID = DefaultWorkerID()
+	}
+	if opts.ClaimLease <= 0 {
+		opts.ClaimLease = DefaultClaimLease
+	}
+	opts.Clock = clock.OrSystem(opts.Clock)
+	if opts.Name == "" {
+		opts.Name = MailJobName
+	}
+	if opts.DryRun {
+		opts.History = nil
+		opts.Ledger = nil
 	}
 
-	now := time.Now()
+	j := &mailJob{
+		subService:     subService,
+		weatherService: weatherService,
+		opts:           opts,
+		state:          newRunState(opts.Name, opts.WorkerID, opts.History, opts.Clock, logger),
+		now:            opts.Clock.Now(),
+		logger:         logger,
+	}
+	// History writes must survive the cancellation that interrupts the run.
+	historyCtx := context.WithoutCancel(ctx)
+	j.state.start(historyCtx)
+	summary := j.state.summary
+	summary.DryRun = opts.DryRun
+	// weather holds one lookup per city and language for the whole run.
+	weather := map[string]lookup{}
+	seenCities := map[string]bool{}
 
-	for _, sub := range subs {
-		if sub.Frequency != "hourly" && sub.Frequency != "daily" {
-			continue
+	var afterID int64
+	for ctx.Err() == nil {
+		page, err := j.nextPage(ctx, afterID)
+		if err != nil {
+			logger.Error("failed to claim due subscriptions", zap.Int64("after_id", afterID), zap.Error(err))
+			err = fmt.Errorf("failed to claim due subscriptions: %w", err)
+			j.state.finish(historyCtx, model.JobRunFailed, err)
+			return summary, tracing.Error(span, err)
 		}
-		if sub.Frequency == "daily" && sub.LastSentAt != nil && now.Sub(*sub.LastSentAt) < 23*time.Hour {
-			continue
+		if len(page) == 0 {
+			break
 		}
+		afterID = page[len(page)-1].ID
+		summary.Total += len(page)
 
-		weather, err := weatherService.GetWeather(sub.City)
-		if err != nil {
-			pkg.Logger.Warn("failed to get weather", zap.String("city", sub.City), zap.Error(err))
-			continue
+		byCity := map[string][]*model.Subscription{}
+		var cities, missing []string
+		for _, sub := range page {
+			seenCities[cityKey(sub.City)] = true
+			key := lookupKey(sub)
+			if _, ok := byCity[key]; !ok {
+				cities = append(cities, key)
+				if _, known := weather[key]; !known {
+					missing = append(missing, key)
+				}
+			}
+			byCity[key] = append(byCity[key], sub)
+		}
+		for city, l := range j.fetchWeather(ctx, missing, byCity) {
+			weather[city] = l
+		}
+
+		var deliveries []delivery
+		for _, city := range cities {
+			l := weather[city]
+			if l.err != nil {
+				for _, sub := range byCity[city] {
+					j.state.failed(sub, ReasonWeatherUnavailable, l.err)
+				}
+				j.observe(metrics.OutcomeFailed, len(byCity[city]))
+				continue
+			}
+			for _, sub := range byCity[city] {
+				deliveries = append(deliveries, delivery{sub: sub, weather: l.weather})
+			}
+		}
+		j.deliver(ctx, deliveries)
+		j.state.flush(historyCtx)
+
+		if len(page) < opts.BatchSize {
+			break
 		}
+	}
+	summary.Cities = len(seenCities)
+	sort.Slice(summary.Previews, func(a, b int) bool {
+		return summary.Previews[a].SubscriptionID < summary.Previews[b].SubscriptionID
+	})
 
-		body := fmt.Sprintf(
-			"Hello!\n\nWeather in %s:\nTemperature: %.1f°C\nHumidity: %d%%\nDescription: %s\n\nTo unsubscribe: %s/api/unsubscribe/%s",
-			sub.City, weather.Temperature, weather.Humidity, weather.Description, os.Getenv("BASE_URL"), sub.UnsubscribeToken,
+	span.SetAttributes(
+		attribute.Int("mail_job.subscriptions", summary.Total),
+		attribute.Int("mail_job.cities", summary.Cities),
+		attribute.Int("mail_job.sent", summary.Sent),
+		attribute.Int("mail_job.caught_up", summary.CaughtUp),
+		attribute.Int("mail_job.skipped", summary.Skipped),
+		attribute.Int("mail_job.failed", summary.Failed),
+	)
+	if err := ctx.Err(); err != nil {
+		logger.Warn("mail job interrupted, stopping at checkpoint",
+			zap.Int("sent", summary.Sent),
+			zap.Int("skipped", summary.SkippedByReason[ReasonInterrupted]),
+			zap.Int64("last_id", afterID),
 		)
-		if err := subService.SendWeatherUpdate(sub.Email, body); err != nil {
-			pkg.Logger.Warn("failed to send email", zap.String("email", sub.Email), zap.Error(err))
-			continue
+		err = fmt.Errorf("%w after %d sent: %w", ErrInterrupted, summary.Sent, err)
+		j.state.finish(historyCtx, model.JobRunInterrupted, err)
+		return summary, tracing.Error(span, err)
+	}
+	j.state.finish(historyCtx, model.JobRunSucceeded, nil)
+	return summary, nil
+}
+
+// nextPage claims the next page of due subscriptions, or only lists it on a
+// dry run.
+func (j *mailJob) nextPage(ctx context.Context, afterID int64) ([]*model.Subscription, error) {
+	if j.opts.DryRun {
+		return j.subService.ListDue(ctx, j.now, afterID, j.opts.BatchSize, j.opts.Scope)
+	}
+	return j.subService.ClaimDue(ctx, j.now, afterID, j.opts.BatchSize, j.opts.Scope, j.opts.WorkerID, j.opts.ClaimLease)
+}
+
+func (j *mailJob) observe(outcome string, n int) {
+	if j.opts.DryRun {
+		return
+	}
+	metrics.MailJobSubscribers.WithLabelValues(outcome).Add(float64(n))
+}
+
+func cityKey(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+// lookupKey groups subscriptions that share a weather lookup: the same city
+// described in the same language.
+func lookupKey(sub *model.Subscription) string {
+	return cityKey(sub.City) + "|" + i18n.Normalize(sub.Language)
+}
+
+// fetchWeather looks up each city once per language.
+func (j *mailJob) fetchWeather(ctx context.Context, cities []string, byCity map[string][]*model.Subscription) map[string]lookup {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[string]lookup, len(cities))
+		sem    = make(chan struct{}, j.opts.Concurrency)
+	)
+	for _, city := range cities {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(city string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			name, lang := byCity[city][0].City, byCity[city][0].Language
+			w, err := j.weatherService.GetLocalizedWeather(ctx, name, lang)
+			if err != nil {
+				j.logger.Warn("failed to get weather",
+					zap.String("city", name),
+					zap.Int("subscribers", len(byCity[city])),
+					zap.Error(err),
+				)
+			}
+			mu.Lock()
+			result[city] = lookup{weather: w, err: err}
+			mu.Unlock()
+		}(city)
+	}
+	wg.Wait()
+	return result
+}
+
+// deliver queues the emails through a pool of workers. Cancelling ctx stops the
+// workers from picking up new deliveries; a delivery already in progress is
+// finished.
+func (j *mailJob) deliver(ctx context.Context, deliveries []delivery) {
+	queue := make(chan delivery)
+	subCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < j.opts.Concurrency && i < len(deliveries); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range queue {
+				if ctx.Err() != nil {
+					j.state.skipped(d.sub, ReasonInterrupted)
+					continue
+				}
+				j.observe(j.processSubscriber(subCtx, d), 1)
+			}
+		}()
+	}
+
+	for i, d := range deliveries {
+		select {
+		case <-ctx.Done():
+			for _, rest := range deliveries[i:] {
+				j.state.skipped(rest.sub, ReasonInterrupted)
+			}
+			close(queue)
+			wg.Wait()
+			return
+		case queue <- d:
 		}
+	}
+	close(queue)
+	wg.Wait()
+}
+
+func (j *mailJob) processSubscriber(ctx context.Context, d delivery) string {
+	sub := d.sub
+	ctx, span := tracer.Start(ctx, "MailJob.subscriber")
+	defer span.End()
+	span.SetAttributes(
+		attribute.Int64("subscription.id", sub.ID),
+		attribute.String("subscription.frequency", sub.Frequency),
+	)
 
-		sub.LastSentAt = &now
-		if err := subService.Update(sub); err != nil {
-			pkg.Logger.Warn("failed to update last sent time", zap.String("email", sub.Email), zap.Error(err))
-			continue
+	if channel, _ := sub.Target(); j.opts.Channels != nil && !j.opts.Channels.Has(channel) {
+		j.state.failed(sub, ReasonChannelUnavailable, fmt.Errorf("channel %q is not configured", channel))
+		return metrics.OutcomeFailed
+	}
+
+	entry, outcome, ok := j.reserve(ctx, sub)
+	if !ok {
+		return outcome
+	}
+
+	caughtUp := overdue(sub, j.now)
+	if j.opts.DryRun {
+		email, err := templates.Render(sub.Language, templates.WeatherUpdate, templates.WeatherUpdateData{
+			BaseURL:          j.opts.BaseURL,
+			City:             sub.City,
+			Weather:          *d.weather,
+			UnsubscribeToken: sub.UnsubscribeToken,
+		})
+		if err != nil {
+			j.state.failed(sub, ReasonRenderFailed, err)
+			return metrics.OutcomeFailed
 		}
+		j.state.preview(sub, email, caughtUp)
+		return metrics.OutcomeProcessed
+	}
+	now := j.now
+	sub.LastSentAt = &now
+	var deliveryID *int64
+	if entry != nil {
+		deliveryID = &entry.ID
+	}
+	// The ledger entry stays pending until the outbox dispatcher settles it.
+	if err := j.subService.QueueWeatherUpdate(ctx, sub, d.weather, deliveryID); err != nil {
+		j.logger.Warn("failed to queue email", zap.String("email", sub.Email), zap.Error(err))
+		_ = tracing.Error(span, err)
+		j.markFailed(ctx, entry, err)
+		j.state.failed(sub, ReasonQueueFailed, err)
+		return metrics.OutcomeFailed
 	}
-	return nil
+	j.state.sent(sub, caughtUp)
+	return metrics.OutcomeProcessed
 }
@@ -0,0 +1,50 @@
+package scheduler
+
+const (
+	ReasonWeatherUnavailable = "weather_unavailable"
+	ReasonSendFailed         = "send_failed"
+	ReasonQueueFailed        = "queue_failed"
+	ReasonInterrupted        = "interrupted"
+	ReasonAlreadyDelivered   = "already_delivered"
+	ReasonLedgerUnavailable  = "ledger_unavailable"
+	ReasonRenderFailed       = "render_failed"
+	ReasonChannelUnavailable = "channel_unavailable"
+)
+
+// Summary is the outcome of a single MailJob run. Sent counts emails handed to
+// the outbox; the dispatcher reports the actual sends. CaughtUp counts the
+// sent subscribers that had missed a whole period, e.g. after downtime. On a
+// dry run Sent counts the previews instead.
+type Summary struct {
+	RunID           int64          `json:"run_id,omitempty"`
+	Total           int            `json:"total"`
+	Cities          int            `json:"cities"`
+	Sent            int            `json:"sent"`
+	CaughtUp        int            `json:"caught_up"`
+	Skipped         int            `json:"skipped"`
+	Failed          int            `json:"failed"`
+	SkippedByReason map[string]int `json:"skipped_by_reason"`
+	FailedByReason  map[string]int `json:"failed_by_reason"`
+	DryRun          bool           `json:"dry_run,omitempty"`
+	Previews        []Preview      `json:"previews,omitempty"`
+}
+
+// Preview is an email a dry run would have queued, with Body holding its
+// plain-text part.
+type Preview struct {
+	SubscriptionID int64  `json:"subscription_id"`
+	Email          string `json:"email"`
+	Channel        string `json:"channel"`
+	City           string `json:"city"`
+	Frequency      string `json:"frequency"`
+	Subject        string `json:"subject"`
+	Body           string `json:"body"`
+	HTML           string `json:"html"`
+}
+
+func newSummary() *Summary {
+	return &Summary{
+		SkippedByReason: map[string]int{},
+		FailedByReason:  map[string]int{},
+	}
+}
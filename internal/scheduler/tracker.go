@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/health"
+)
+
+// Tracker records the state of scheduled job runs for readiness reporting.
+type Tracker struct {
+	mu     sync.Mutex
+	status health.JobStatus
+}
+
+var _ health.JobStatusReporter = (*Tracker)(nil)
+
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+func (t *Tracker) Run(job func() error) error {
+	start := time.Now()
+	t.mu.Lock()
+	t.status.Running = true
+	t.status.LastStartedAt = &start
+	t.mu.Unlock()
+
+	err := job()
+
+	end := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.Running = false
+	t.status.LastFinishedAt = &end
+	t.status.LastError = ""
+	if err != nil {
+		t.status.LastError = err.Error()
+	}
+	return err
+}
+
+func (t *Tracker) Status() health.JobStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
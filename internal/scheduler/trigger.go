@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+)
+
+// Trigger runs the mail job on demand, outside the hourly schedule.
+type Trigger struct {
+	subService     *service.SubscriptionService
+	weatherService *service.WeatherService
+	opts           Options
+	locker         Locker
+	logger         *zap.Logger
+}
+
+func NewTrigger(
+	subService *service.SubscriptionService,
+	weatherService *service.WeatherService,
+	opts Options,
+	locker Locker,
+	logger *zap.Logger,
+) *Trigger {
+	return &Trigger{
+		subService:     subService,
+		weatherService: weatherService,
+		opts:           opts,
+		locker:         locker,
+		logger:         pkg.OrNop(logger),
+	}
+}
+
+// Run runs the mail job for the due subscriptions in scope. A real run takes
+// the same lock as the scheduled job and returns ErrLocked while that is
+// running; a dry run only reads, so it runs regardless.
+func (t *Trigger) Run(ctx context.Context, scope service.Scope, dryRun bool) (*Summary, error) {
+	opts := t.opts
+	opts.Name = ManualMailJobName
+	opts.Scope = scope
+	opts.DryRun = dryRun
+
+	logger := pkg.FromContext(ctx, t.logger)
+	logger.Info("Mail job triggered manually",
+		zap.String("city", scope.City),
+		zap.Int64("subscription_id", scope.SubscriptionID),
+		zap.Bool("dry_run", dryRun),
+	)
+	if dryRun {
+		return MailJob(ctx, t.subService, t.weatherService, opts, logger)
+	}
+	var summary *Summary
+	err := RunLocked(ctx, t.locker, MailJobLockName, func() error {
+		var err error
+		summary, err = MailJob(ctx, t.subService, t.weatherService, opts, logger)
+		return err
+	})
+	return summary, err
+}
@@ -1,134 +1,352 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
 	"go.uber.org/zap"
 )
 
 var ErrNotFound = errors.New("subscription not found")
 
+var tracer = tracing.Tracer("github.com/l4ndm1nes/Weather-API-Application/internal/service")
+
 type SubscriptionRepository interface {
-	Create(sub *model.Subscription) error
-	FindByEmail(email string) (*model.Subscription, error)
-	GetByToken(token string) (*model.Subscription, error)
-	Update(sub *model.Subscription) error
-	UnsubscribeByToken(token string) error
-	GetAllConfirmed() ([]*model.Subscription, error)
+	Create(ctx context.Context, sub *model.Subscription) error
+	CreateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error
+	FindByEmail(ctx context.Context, email string) (*model.Subscription, error)
+	GetByToken(ctx context.Context, token string) (*model.Subscription, error)
+	Update(ctx context.Context, sub *model.Subscription) error
+	UpdateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error
+	UnsubscribeByToken(ctx context.Context, token string) error
+	GetAllConfirmed(ctx context.Context) ([]*model.Subscription, error)
+	ListDue(ctx context.Context, q DueQuery) ([]*model.Subscription, error)
+	ClaimDue(ctx context.Context, q ClaimQuery) ([]*model.Subscription, error)
+}
+
+// DueQuery selects one page of confirmed subscriptions that are due for a
+// weather update, ordered by ID. A subscription is due when it has never been
+// sent or was last sent before the cutoff for its frequency.
+type DueQuery struct {
+	HourlySentBefore time.Time
+	DailySentBefore  time.Time
+	AfterID          int64
+	Limit            int
+	Scope
+}
+
+// Scope narrows due subscriptions to one city (matched case-insensitively) or
+// one subscription. The zero value matches every subscription.
+type Scope struct {
+	City           string
+	SubscriptionID int64
+}
+
+// ClaimQuery is a DueQuery that also claims the returned rows for Owner until
+// Until, skipping rows whose claim is still live at Now.
+type ClaimQuery struct {
+	DueQuery
+	Owner string
+	Now   time.Time
+	Until time.Time
 }
 
+// dailyInterval leaves an hour of slack so a run that starts a little late does
+// not push the next daily email back by a whole day.
+const dailyInterval = 23 * time.Hour
+
 type Mailer interface {
-	SendConfirmation(email, token string) error
-	SendWeatherUpdate(email, city string, weatherInfo string) error
+	SendConfirmation(ctx context.Context, email, token, lang string) error
+	SendWeatherUpdate(ctx context.Context, email string, update model.WeatherUpdate) error
+}
+
+// ChannelValidator rejects channels and destinations that weather updates
+// cannot be delivered to. Verify runs the channel's handshake with the
+// destination, if it has one, when the subscription is confirmed.
+type ChannelValidator interface {
+	Validate(channel, destination string) error
+	Verify(ctx context.Context, target notify.Target) error
+}
+
+type SuppressionChecker interface {
+	IsSuppressed(ctx context.Context, email string) (bool, error)
 }
 
 type SubscriptionService struct {
 	Repo   SubscriptionRepository
 	Mailer Mailer
+	// Channels, when set, validates the channel and destination of new
+	// subscriptions and verifies webhook destinations.
+	Channels ChannelValidator
+	// Suppressions, when set, refuses addresses that bounced or complained.
+	Suppressions SuppressionChecker
+	// Clock stamps queued emails. It defaults to the wall clock.
+	Clock  clock.Clock
+	logger *zap.Logger
+}
+
+func NewSubscriptionService(repo SubscriptionRepository, mailer Mailer, logger *zap.Logger) *SubscriptionService {
+	return &SubscriptionService{Repo: repo, Mailer: mailer, Clock: clock.System, logger: pkg.OrNop(logger)}
 }
 
-func NewSubscriptionService(repo SubscriptionRepository, mailer Mailer) *SubscriptionService {
-	return &SubscriptionService{Repo: repo, Mailer: mailer}
+func (s *SubscriptionService) log(ctx context.Context) *zap.Logger {
+	return pkg.FromContext(ctx, s.logger)
 }
 
-func generateToken() (string, error) {
+func generateToken(ctx context.Context) (string, error) {
+	_, span := tracer.Start(ctx, "generateToken")
+	defer span.End()
 	return uuid.New().String(), nil
 }
 
-func (s *SubscriptionService) Subscribe(sub *model.Subscription) (*model.Subscription, error) {
-	existing, err := s.Repo.FindByEmail(sub.Email)
+// generateSecret returns 32 random bytes, hex encoded.
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *SubscriptionService) Subscribe(ctx context.Context, sub *model.Subscription) (*model.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Subscribe")
+	defer span.End()
+
+	if sub.Channel == "" {
+		sub.Channel = model.ChannelEmail
+	}
+	// Email updates may only go to the address that receives the confirmation,
+	// or anyone could confirm their own inbox and mail a third party.
+	if sub.Channel == model.ChannelEmail {
+		if sub.Destination != "" && !strings.EqualFold(strings.TrimSpace(sub.Destination), strings.TrimSpace(sub.Email)) {
+			return nil, tracing.Error(span, fmt.Errorf("%w: email updates go to the subscribed address", notify.ErrInvalidDestination))
+		}
+		sub.Destination = ""
+	}
+	if s.Channels != nil {
+		if err := s.Channels.Validate(sub.Channel, sub.Destination); err != nil {
+			return nil, tracing.Error(span, err)
+		}
+	}
+	if sub.Channel == model.ChannelWebhook {
+		secret, err := generateSecret()
+		if err != nil {
+			s.log(ctx).Error("failed to generate webhook secret", zap.Error(err))
+			return nil, tracing.Error(span, errors.New("failed generating token"))
+		}
+		sub.WebhookSecret = secret
+	}
+
+	if s.Suppressions != nil {
+		suppressed, err := s.Suppressions.IsSuppressed(ctx, sub.Email)
+		if err != nil {
+			s.log(ctx).Error("failed to check suppression list", zap.Error(err))
+			return nil, tracing.Error(span, err)
+		}
+		if suppressed {
+			return nil, tracing.Error(span, ErrSuppressed)
+		}
+	}
+
+	existing, err := s.Repo.FindByEmail(ctx, sub.Email)
 	if err != nil && !errors.Is(err, ErrNotFound) && err.Error() != "record not found" {
-		pkg.Logger.Error("failed to check existing subscription", zap.Error(err))
-		return nil, err
+		s.log(ctx).Error("failed to check existing subscription", zap.Error(err))
+		return nil, tracing.Error(span, err)
 	}
 	if existing != nil {
-		return nil, errors.New("email already subscribed")
+		return nil, tracing.Error(span, errors.New("email already subscribed"))
 	}
 
-	confirmToken, err := generateToken()
+	confirmToken, err := generateToken(ctx)
 	if err != nil {
-		pkg.Logger.Error("failed to generate confirm token", zap.Error(err))
-		return nil, errors.New("failed generating token")
+		s.log(ctx).Error("failed to generate confirm token", zap.Error(err))
+		return nil, tracing.Error(span, errors.New("failed generating token"))
 	}
 
-	unsubscribeToken, err := generateToken()
+	unsubscribeToken, err := generateToken(ctx)
 	if err != nil {
-		pkg.Logger.Error("failed to generate unsubscribe token", zap.Error(err))
-		return nil, errors.New("failed generating token")
+		s.log(ctx).Error("failed to generate unsubscribe token", zap.Error(err))
+		return nil, tracing.Error(span, errors.New("failed generating token"))
 	}
 
 	sub.ConfirmToken = confirmToken
 	sub.UnsubscribeToken = unsubscribeToken
 	sub.Confirmed = false
+	sub.Language = i18n.Normalize(sub.Language)
 
-	if err := s.Repo.Create(sub); err != nil {
-		pkg.Logger.Error("failed to create subscription", zap.Error(err))
-		return nil, err
+	confirmation := &model.OutboxMessage{
+		Kind:          model.OutboxConfirmation,
+		Recipient:     sub.Email,
+		Payload:       model.OutboxPayload{Token: confirmToken, Language: sub.Language},
+		NextAttemptAt: s.Clock.Now(),
 	}
-
-	subCreated, err := s.Repo.FindByEmail(sub.Email)
-	if err != nil {
-		pkg.Logger.Error("failed to retrieve created subscription", zap.Error(err))
-		return nil, err
+	if err := s.Repo.CreateWithOutbox(ctx, sub, confirmation); err != nil {
+		s.log(ctx).Error("failed to create subscription", zap.Error(err))
+		return nil, tracing.Error(span, err)
 	}
 
-	if err := s.Mailer.SendConfirmation(subCreated.Email, confirmToken); err != nil {
-		pkg.Logger.Error("failed to send confirmation email", zap.String("email", subCreated.Email), zap.Error(err))
+	subCreated, err := s.Repo.FindByEmail(ctx, sub.Email)
+	if err != nil {
+		s.log(ctx).Error("failed to retrieve created subscription", zap.Error(err))
+		return nil, tracing.Error(span, err)
 	}
 	return subCreated, nil
 }
 
-func (s *SubscriptionService) ConfirmSubscription(token string) error {
-	sub, err := s.Repo.GetByToken(token)
+func (s *SubscriptionService) ConfirmSubscription(ctx context.Context, token string) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ConfirmSubscription")
+	defer span.End()
+
+	sub, err := s.Repo.GetByToken(ctx, token)
 	if err != nil {
-		pkg.Logger.Error("failed to get subscription by token", zap.String("token", token), zap.Error(err))
-		return errors.New("subscription not found")
+		s.log(ctx).Error("failed to get subscription by token", zap.String("token", token), zap.Error(err))
+		return tracing.Error(span, errors.New("subscription not found"))
 	}
 
 	if sub.Confirmed {
-		return errors.New("already confirmed")
+		return tracing.Error(span, errors.New("already confirmed"))
+	}
+	// The destination handshake waits for the confirmation, so the public
+	// subscribe endpoint never makes the server contact a destination. A
+	// failed handshake leaves the subscription unconfirmed and the link valid.
+	if s.Channels != nil {
+		target := notify.Target{Channel: sub.Channel, Destination: sub.Destination, Secret: sub.WebhookSecret}
+		if err := s.Channels.Verify(ctx, target); err != nil {
+			s.log(ctx).Warn("notification destination verification failed", zap.String("channel", sub.Channel), zap.Error(err))
+			return tracing.Error(span, err)
+		}
 	}
 
 	sub.Confirmed = true
-	if err := s.Repo.Update(sub); err != nil {
-		pkg.Logger.Error("failed to update subscription as confirmed", zap.Error(err))
-		return err
+	if err := s.Repo.Update(ctx, sub); err != nil {
+		s.log(ctx).Error("failed to update subscription as confirmed", zap.Error(err))
+		return tracing.Error(span, err)
 	}
 	return nil
 }
 
-func (s *SubscriptionService) Unsubscribe(token string) error {
-	if err := s.Repo.UnsubscribeByToken(token); err != nil {
-		pkg.Logger.Error("failed to unsubscribe by token", zap.String("token", token), zap.Error(err))
-		return err
+func (s *SubscriptionService) Unsubscribe(ctx context.Context, token string) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Unsubscribe")
+	defer span.End()
+
+	if err := s.Repo.UnsubscribeByToken(ctx, token); err != nil {
+		s.log(ctx).Error("failed to unsubscribe by token", zap.String("token", token), zap.Error(err))
+		return tracing.Error(span, err)
 	}
 	return nil
 }
 
-func (s *SubscriptionService) GetAllConfirmed() ([]*model.Subscription, error) {
-	subs, err := s.Repo.GetAllConfirmed()
+func (s *SubscriptionService) GetAllConfirmed(ctx context.Context) ([]*model.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetAllConfirmed")
+	defer span.End()
+
+	subs, err := s.Repo.GetAllConfirmed(ctx)
+	if err != nil {
+		s.log(ctx).Error("failed to get all confirmed subscriptions", zap.Error(err))
+		return nil, tracing.Error(span, err)
+	}
+	return subs, nil
+}
+
+func (s *SubscriptionService) ListDue(ctx context.Context, now time.Time, afterID int64, limit int, scope Scope) ([]*model.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ListDue")
+	defer span.End()
+
+	subs, err := s.Repo.ListDue(ctx, dueQuery(now, afterID, limit, scope))
 	if err != nil {
-		pkg.Logger.Error("failed to get all confirmed subscriptions", zap.Error(err))
-		return nil, err
+		s.log(ctx).Error("failed to list due subscriptions", zap.Error(err))
+		return nil, tracing.Error(span, err)
 	}
 	return subs, nil
 }
 
-func (s *SubscriptionService) Update(sub *model.Subscription) error {
-	if err := s.Repo.Update(sub); err != nil {
-		pkg.Logger.Error("failed to update subscription", zap.Error(err))
-		return err
+func (s *SubscriptionService) ClaimDue(ctx context.Context, now time.Time, afterID int64, limit int, scope Scope, owner string, lease time.Duration) ([]*model.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ClaimDue")
+	defer span.End()
+
+	subs, err := s.Repo.ClaimDue(ctx, ClaimQuery{
+		DueQuery: dueQuery(now, afterID, limit, scope),
+		Owner:    owner,
+		Now:      now,
+		Until:    now.Add(lease),
+	})
+	if err != nil {
+		s.log(ctx).Error("failed to claim due subscriptions", zap.Error(err))
+		return nil, tracing.Error(span, err)
+	}
+	return subs, nil
+}
+
+func dueQuery(now time.Time, afterID int64, limit int, scope Scope) DueQuery {
+	return DueQuery{
+		HourlySentBefore: now,
+		DailySentBefore:  now.Add(-dailyInterval),
+		AfterID:          afterID,
+		Limit:            limit,
+		Scope:            scope,
+	}
+}
+
+func (s *SubscriptionService) Update(ctx context.Context, sub *model.Subscription) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Update")
+	defer span.End()
+
+	if err := s.Repo.Update(ctx, sub); err != nil {
+		s.log(ctx).Error("failed to update subscription", zap.Error(err))
+		return tracing.Error(span, err)
 	}
 	return nil
 }
 
-func (s *SubscriptionService) SendWeatherUpdate(email, body string) error {
-	if err := s.Mailer.SendWeatherUpdate(email, "", body); err != nil {
-		pkg.Logger.Error("failed to send weather update", zap.String("email", email), zap.Error(err))
-		return err
+// QueueWeatherUpdate saves sub and queues its weather email in one
+// transaction. deliveryID links the email to its ledger entry, if any.
+func (s *SubscriptionService) QueueWeatherUpdate(ctx context.Context, sub *model.Subscription, weather *model.Weather, deliveryID *int64) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.QueueWeatherUpdate")
+	defer span.End()
+
+	channel, destination := sub.Target()
+	msg := &model.OutboxMessage{
+		Kind:      model.OutboxWeatherUpdate,
+		Recipient: sub.Email,
+		Payload: model.OutboxPayload{
+			Token:       sub.UnsubscribeToken,
+			City:        sub.City,
+			Weather:     weather,
+			Language:    sub.Language,
+			Channel:     channel,
+			Destination: destination,
+			Secret:      sub.WebhookSecret,
+		},
+		DeliveryID:    deliveryID,
+		NextAttemptAt: s.Clock.Now(),
+	}
+	if err := s.Repo.UpdateWithOutbox(ctx, sub, msg); err != nil {
+		s.log(ctx).Error("failed to queue weather update", zap.String("email", sub.Email), zap.Error(err))
+		return tracing.Error(span, err)
+	}
+	return nil
+}
+
+func (s *SubscriptionService) SendWeatherUpdate(ctx context.Context, sub *model.Subscription, weather *model.Weather) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.SendWeatherUpdate")
+	defer span.End()
+
+	update := model.WeatherUpdate{City: sub.City, Weather: *weather, UnsubscribeToken: sub.UnsubscribeToken, Language: sub.Language}
+	if err := s.Mailer.SendWeatherUpdate(ctx, sub.Email, update); err != nil {
+		s.log(ctx).Error("failed to send weather update", zap.String("email", sub.Email), zap.Error(err))
+		return tracing.Error(span, err)
 	}
 	return nil
 }
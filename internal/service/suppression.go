@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var ErrSuppressed = errors.New("email address is suppressed")
+
+type SuppressionRepository interface {
+	Suppress(ctx context.Context, s *model.Suppression) error
+	IsSuppressed(ctx context.Context, email string) (bool, error)
+}
+
+// SuppressionService keeps the list of addresses that bounced or complained,
+// which Subscribe, the mail job and the outbox dispatcher never email.
+type SuppressionService struct {
+	Repo   SuppressionRepository
+	logger *zap.Logger
+}
+
+func NewSuppressionService(repo SuppressionRepository, logger *zap.Logger) *SuppressionService {
+	return &SuppressionService{Repo: repo, logger: pkg.OrNop(logger)}
+}
+
+// Process suppresses the addresses of hard bounces and complaints reported by
+// source and returns how many events did so. Soft bounces and unknown event
+// types are skipped.
+func (s *SuppressionService) Process(ctx context.Context, source string, events []model.EmailEvent) (int, error) {
+	ctx, span := tracer.Start(ctx, "SuppressionService.Process")
+	defer span.End()
+	span.SetAttributes(attribute.String("email_events.source", source), attribute.Int("email_events.count", len(events)))
+
+	suppressed := 0
+	for _, e := range events {
+		if e.Email == "" || e.Soft || (e.Type != model.EmailEventBounce && e.Type != model.EmailEventComplaint) {
+			continue
+		}
+		err := s.Repo.Suppress(ctx, &model.Suppression{Email: e.Email, Reason: e.Type, Detail: e.Detail, Source: source})
+		if err != nil {
+			pkg.FromContext(ctx, s.logger).Error("failed to suppress email", zap.String("email", e.Email), zap.Error(err))
+			return suppressed, tracing.Error(span, err)
+		}
+		suppressed++
+	}
+	return suppressed, nil
+}
+
+func (s *SuppressionService) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	return s.Repo.IsSuppressed(ctx, email)
+}
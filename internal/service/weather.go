@@ -1,21 +1,105 @@
 package service
 
 import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/tracing"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type WeatherProvider interface {
-	GetWeather(city string) (*model.Weather, error)
+	GetWeather(ctx context.Context, city string) (*model.Weather, error)
+}
+
+// LocalizedWeatherProvider is a WeatherProvider that can describe the weather
+// in another language.
+type LocalizedWeatherProvider interface {
+	GetLocalizedWeather(ctx context.Context, city, lang string) (*model.Weather, error)
 }
 
 type WeatherService struct {
 	Provider WeatherProvider
+	// Clock expires cached lookups. It defaults to the wall clock.
+	Clock clock.Clock
+
+	cacheTTL time.Duration
+	cache    *weatherCache
 }
 
 func NewWeatherService(provider WeatherProvider) *WeatherService {
-	return &WeatherService{Provider: provider}
+	return &WeatherService{Provider: provider, Clock: clock.System}
+}
+
+// NewCachedWeatherService keeps up to size successful lookups for ttl so bursts
+// of requests for the same city reach the provider only once. A size of zero
+// means DefaultWeatherCacheSize.
+func NewCachedWeatherService(provider WeatherProvider, ttl time.Duration, size int) *WeatherService {
+	return &WeatherService{
+		Provider: provider,
+		Clock:    clock.System,
+		cacheTTL: ttl,
+		cache:    newWeatherCache(size),
+	}
+}
+
+// CacheLen is the number of cached lookups.
+func (ws *WeatherService) CacheLen() int {
+	if ws.cache == nil {
+		return 0
+	}
+	return ws.cache.len()
+}
+
+func (ws *WeatherService) GetWeather(ctx context.Context, city string) (*model.Weather, error) {
+	return ws.GetLocalizedWeather(ctx, city, i18n.Default)
+}
+
+// GetLocalizedWeather describes the weather in lang when the provider supports
+// it, and in English otherwise.
+func (ws *WeatherService) GetLocalizedWeather(ctx context.Context, city, lang string) (*model.Weather, error) {
+	ctx, span := tracer.Start(ctx, "WeatherService.GetWeather")
+	defer span.End()
+
+	lang = i18n.Normalize(lang)
+	if _, ok := ws.Provider.(LocalizedWeatherProvider); !ok {
+		lang = i18n.Default
+	}
+	span.SetAttributes(attribute.String("weather.city", city), attribute.String("weather.lang", lang))
+
+	if ws.cacheTTL <= 0 || ws.cache == nil {
+		weather, err := ws.fetch(ctx, city, lang)
+		return weather, tracing.Error(span, err)
+	}
+
+	key := strings.ToLower(strings.TrimSpace(city)) + "|" + lang
+	now := ws.Clock.Now()
+
+	if cached, ok := ws.cache.get(key, now); ok {
+		metrics.WeatherCacheRequests.WithLabelValues(metrics.CacheHit).Inc()
+		span.SetAttributes(attribute.Bool("weather.cache_hit", true))
+		return cached, nil
+	}
+	metrics.WeatherCacheRequests.WithLabelValues(metrics.CacheMiss).Inc()
+	span.SetAttributes(attribute.Bool("weather.cache_hit", false))
+
+	weather, err := ws.fetch(ctx, city, lang)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	ws.cache.put(key, weather, now.Add(ws.cacheTTL))
+	return weather, nil
 }
 
-func (ws *WeatherService) GetWeather(city string) (*model.Weather, error) {
-	return ws.Provider.GetWeather(city)
+func (ws *WeatherService) fetch(ctx context.Context, city, lang string) (*model.Weather, error) {
+	if localized, ok := ws.Provider.(LocalizedWeatherProvider); ok && lang != i18n.Default {
+		return localized.GetLocalizedWeather(ctx, city, lang)
+	}
+	return ws.Provider.GetWeather(ctx, city)
 }
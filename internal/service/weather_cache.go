@@ -0,0 +1,74 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+)
+
+// DefaultWeatherCacheSize bounds the weather cache when no size is given.
+const DefaultWeatherCacheSize = 1000
+
+type cachedWeather struct {
+	key       string
+	weather   *model.Weather
+	expiresAt time.Time
+}
+
+// weatherCache is a size-bounded LRU of weather lookups. Its keys come from
+// the public /api/weather query, so it evicts the least recently used entry
+// when full and drops expired entries when it meets them.
+type weatherCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newWeatherCache(size int) *weatherCache {
+	if size <= 0 {
+		size = DefaultWeatherCacheSize
+	}
+	return &weatherCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *weatherCache) get(key string, now time.Time) (*model.Weather, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cachedWeather)
+	if !now.Before(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.weather, true
+}
+
+func (c *weatherCache) put(key string, weather *model.Weather, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = &cachedWeather{key: key, weather: weather, expiresAt: expiresAt}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cachedWeather{key: key, weather: weather, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedWeather).key)
+	}
+}
+
+func (c *weatherCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,369 @@
+package simulation
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+)
+
+var (
+	_ service.SubscriptionRepository = (*Subscriptions)(nil)
+	_ outbox.Store                   = (*Outbox)(nil)
+	_ scheduler.Ledger               = (*Ledger)(nil)
+	_ outbox.Ledger                  = (*Ledger)(nil)
+	_ scheduler.History              = (*History)(nil)
+	_ scheduler.RunLog               = (*History)(nil)
+	_ service.Mailer                 = (*Mailer)(nil)
+)
+
+// Subscriptions is an in-memory subscription repository that applies the same
+// due and claim rules as the Postgres one.
+type Subscriptions struct {
+	mu     sync.Mutex
+	subs   map[int64]*model.Subscription
+	claims map[int64]time.Time
+	outbox *Outbox
+	nextID int64
+}
+
+func NewSubscriptions(box *Outbox) *Subscriptions {
+	return &Subscriptions{
+		subs:   map[int64]*model.Subscription{},
+		claims: map[int64]time.Time{},
+		outbox: box,
+	}
+}
+
+func (r *Subscriptions) Create(ctx context.Context, sub *model.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sub.ID == 0 {
+		r.nextID++
+		sub.ID = r.nextID
+	} else if sub.ID > r.nextID {
+		r.nextID = sub.ID
+	}
+	stored := *sub
+	r.subs[sub.ID] = &stored
+	return nil
+}
+
+func (r *Subscriptions) CreateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error {
+	if err := r.Create(ctx, sub); err != nil {
+		return err
+	}
+	r.outbox.add(msg)
+	return nil
+}
+
+func (r *Subscriptions) find(match func(*model.Subscription) bool) (*model.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subs {
+		if match(sub) {
+			found := *sub
+			return &found, nil
+		}
+	}
+	return nil, service.ErrNotFound
+}
+
+func (r *Subscriptions) FindByEmail(ctx context.Context, email string) (*model.Subscription, error) {
+	return r.find(func(sub *model.Subscription) bool { return sub.Email == email })
+}
+
+func (r *Subscriptions) GetByToken(ctx context.Context, token string) (*model.Subscription, error) {
+	return r.find(func(sub *model.Subscription) bool { return sub.ConfirmToken == token })
+}
+
+func (r *Subscriptions) Update(ctx context.Context, sub *model.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subs[sub.ID]; !ok {
+		return service.ErrNotFound
+	}
+	stored := *sub
+	r.subs[sub.ID] = &stored
+	return nil
+}
+
+// UpdateWithOutbox only records LastSentAt and releases the claim, like the
+// Postgres repo.
+func (r *Subscriptions) UpdateWithOutbox(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error {
+	r.mu.Lock()
+	stored, ok := r.subs[sub.ID]
+	if ok {
+		stored.LastSentAt = sub.LastSentAt
+		delete(r.claims, sub.ID)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return service.ErrNotFound
+	}
+	r.outbox.add(msg)
+	return nil
+}
+
+func (r *Subscriptions) UnsubscribeByToken(ctx context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, sub := range r.subs {
+		if sub.UnsubscribeToken == token {
+			delete(r.subs, id)
+			return nil
+		}
+	}
+	return service.ErrNotFound
+}
+
+func (r *Subscriptions) GetAllConfirmed(ctx context.Context) ([]*model.Subscription, error) {
+	return r.list(func(sub *model.Subscription) bool { return sub.Confirmed }, 0, nil), nil
+}
+
+func (r *Subscriptions) ListDue(ctx context.Context, q service.DueQuery) ([]*model.Subscription, error) {
+	return r.list(func(sub *model.Subscription) bool { return due(sub, q) }, q.Limit, nil), nil
+}
+
+func (r *Subscriptions) ClaimDue(ctx context.Context, q service.ClaimQuery) ([]*model.Subscription, error) {
+	return r.list(func(sub *model.Subscription) bool {
+		until, claimed := r.claims[sub.ID]
+		return due(sub, q.DueQuery) && (!claimed || until.Before(q.Now))
+	}, q.Limit, func(sub *model.Subscription) {
+		r.claims[sub.ID] = q.Until
+	}), nil
+}
+
+// list returns copies of the matching subscriptions ordered by ID, calling
+// claim on each while the lock is held.
+func (r *Subscriptions) list(match func(*model.Subscription) bool, limit int, claim func(*model.Subscription)) []*model.Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]int64, 0, len(r.subs))
+	for id := range r.subs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var result []*model.Subscription
+	for _, id := range ids {
+		sub := r.subs[id]
+		if !match(sub) {
+			continue
+		}
+		if claim != nil {
+			claim(sub)
+		}
+		found := *sub
+		result = append(result, &found)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result
+}
+
+func due(sub *model.Subscription, q service.DueQuery) bool {
+	if !sub.Confirmed || sub.UndeliverableAt != nil || sub.ID <= q.AfterID {
+		return false
+	}
+	if q.City != "" && !strings.EqualFold(strings.TrimSpace(sub.City), strings.TrimSpace(q.City)) {
+		return false
+	}
+	if q.SubscriptionID != 0 && sub.ID != q.SubscriptionID {
+		return false
+	}
+	var cutoff time.Time
+	switch sub.Frequency {
+	case "hourly":
+		cutoff = q.HourlySentBefore
+	case "daily":
+		cutoff = q.DailySentBefore
+	default:
+		return false
+	}
+	return sub.LastSentAt == nil || sub.LastSentAt.Before(cutoff)
+}
+
+// Outbox is an in-memory outbox store.
+type Outbox struct {
+	mu       sync.Mutex
+	messages []*model.OutboxMessage
+}
+
+func (o *Outbox) add(msg *model.OutboxMessage) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	msg.ID = int64(len(o.messages) + 1)
+	if msg.Status == "" {
+		msg.Status = model.OutboxPending
+	}
+	stored := *msg
+	o.messages = append(o.messages, &stored)
+}
+
+func (o *Outbox) get(id int64) *model.OutboxMessage {
+	return o.messages[id-1]
+}
+
+func (o *Outbox) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var due []*model.OutboxMessage
+	for _, m := range o.messages {
+		if m.Status != model.OutboxPending || m.NextAttemptAt.After(now) {
+			continue
+		}
+		m.NextAttemptAt = now.Add(lease)
+		claimed := *m
+		due = append(due, &claimed)
+		if len(due) == limit {
+			break
+		}
+	}
+	return due, nil
+}
+
+func (o *Outbox) MarkSent(ctx context.Context, id int64, attempts int, at time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.get(id)
+	m.Status, m.Attempts, m.SentAt, m.LastError = model.OutboxSent, attempts, &at, ""
+	return nil
+}
+
+func (o *Outbox) MarkRetry(ctx context.Context, id int64, attempts int, next time.Time, lastErr string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.get(id)
+	m.Attempts, m.NextAttemptAt, m.LastError = attempts, next, lastErr
+	return nil
+}
+
+func (o *Outbox) MarkDead(ctx context.Context, id int64, attempts int, lastErr string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.get(id)
+	m.Status, m.Attempts, m.LastError = model.OutboxDead, attempts, lastErr
+	return nil
+}
+
+// Ledger is an in-memory delivery ledger.
+type Ledger struct {
+	mu      sync.Mutex
+	entries []*model.Delivery
+}
+
+func (l *Ledger) Reserve(ctx context.Context, subscriptionID int64, period time.Time) (*model.Delivery, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.entries {
+		if e.SubscriptionID != subscriptionID || !e.Period.Equal(period) {
+			continue
+		}
+		if e.Status != model.DeliveryFailed {
+			return nil, false, nil
+		}
+		e.Status = model.DeliveryPending
+		e.Attempts++
+		reserved := *e
+		return &reserved, true, nil
+	}
+	e := &model.Delivery{
+		ID:             int64(len(l.entries) + 1),
+		SubscriptionID: subscriptionID,
+		Period:         period,
+		Status:         model.DeliveryPending,
+		Attempts:       1,
+	}
+	l.entries = append(l.entries, e)
+	reserved := *e
+	return &reserved, true, nil
+}
+
+func (l *Ledger) MarkSent(ctx context.Context, id int64, at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.entries[id-1]
+	e.Status, e.SentAt = model.DeliverySent, &at
+	return nil
+}
+
+func (l *Ledger) MarkFailed(ctx context.Context, id int64, reason string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.entries[id-1]
+	e.Status, e.LastError = model.DeliveryFailed, reason
+	return nil
+}
+
+// History is an in-memory job history.
+type History struct {
+	mu   sync.Mutex
+	runs []*model.JobRun
+}
+
+func (h *History) StartRun(ctx context.Context, run *model.JobRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	run.ID = int64(len(h.runs) + 1)
+	stored := *run
+	h.runs = append(h.runs, &stored)
+	return nil
+}
+
+func (h *History) FinishRun(ctx context.Context, run *model.JobRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stored := *run
+	h.runs[run.ID-1] = &stored
+	return nil
+}
+
+func (h *History) AddDeliveries(ctx context.Context, deliveries []*model.JobDelivery) error {
+	return nil
+}
+
+func (h *History) RunsSince(ctx context.Context, name string, since time.Time) ([]*model.JobRun, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var runs []*model.JobRun
+	for _, run := range h.runs {
+		if run.Name == name && !run.StartedAt.Before(since) {
+			copied := *run
+			runs = append(runs, &copied)
+		}
+	}
+	return runs, nil
+}
+
+// Mailer records every email instead of sending it.
+type Mailer struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	sent  []Email
+}
+
+func (m *Mailer) record(e Email) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.At = m.clock.Now()
+	m.sent = append(m.sent, e)
+}
+
+func (m *Mailer) SendConfirmation(ctx context.Context, email, token, lang string) error {
+	m.record(Email{Kind: model.OutboxConfirmation, Recipient: email})
+	return nil
+}
+
+func (m *Mailer) SendWeatherUpdate(ctx context.Context, email string, update model.WeatherUpdate) error {
+	m.record(Email{Kind: model.OutboxWeatherUpdate, Recipient: email, City: update.City})
+	return nil
+}
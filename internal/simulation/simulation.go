@@ -0,0 +1,196 @@
+// Package simulation runs the mail scheduler against in-memory fakes on a
+// virtual clock, so schedule changes can be checked without waiting for real
+// hours to pass.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Outage is a window in which the service is down. Scheduled runs that fall
+// in [From, To) do not happen, and the service starts again at To.
+type Outage struct {
+	From time.Time
+	To   time.Time
+}
+
+type Config struct {
+	Start         time.Time
+	Duration      time.Duration
+	Subscriptions []*model.Subscription
+	Outages       []Outage
+	// CatchUpGrace mirrors MAIL_JOB_CATCHUP_GRACE for restarts after an outage.
+	CatchUpGrace time.Duration
+	// Provider defaults to one that reports the same weather everywhere.
+	Provider service.WeatherProvider
+}
+
+// Email is an email the dispatcher handed to the mailer.
+type Email struct {
+	At        time.Time
+	Kind      string
+	Recipient string
+	City      string
+}
+
+type Run struct {
+	At      time.Time
+	CatchUp bool
+	Summary *scheduler.Summary
+}
+
+type Report struct {
+	Runs   []Run
+	Emails []Email
+}
+
+// PerRecipient counts the emails each recipient got.
+func (r *Report) PerRecipient() map[string]int {
+	counts := map[string]int{}
+	for _, e := range r.Emails {
+		counts[e.Recipient]++
+	}
+	return counts
+}
+
+type fixedWeather struct{}
+
+func (fixedWeather) GetWeather(ctx context.Context, city string) (*model.Weather, error) {
+	return &model.Weather{Temperature: 20, Humidity: 50, Description: "Clear"}, nil
+}
+
+type event struct {
+	at      time.Time
+	restart bool
+}
+
+// Simulate runs the hourly mail job and the outbox dispatcher over
+// cfg.Duration of virtual time and reports every run and email.
+func Simulate(ctx context.Context, cfg Config) (*Report, error) {
+	clk := clock.NewFake(cfg.Start)
+	box := &Outbox{}
+	subs := NewSubscriptions(box)
+	for _, sub := range cfg.Subscriptions {
+		seeded := *sub
+		if err := subs.Create(ctx, &seeded); err != nil {
+			return nil, err
+		}
+	}
+	ledger := &Ledger{}
+	history := &History{}
+	mailer := &Mailer{clock: clk}
+
+	provider := cfg.Provider
+	if provider == nil {
+		provider = fixedWeather{}
+	}
+	subService := service.NewSubscriptionService(subs, mailer, zap.NewNop())
+	subService.Clock = clk
+	weatherService := service.NewWeatherService(provider)
+	weatherService.Clock = clk
+	dispatcher := outbox.NewDispatcher(box, mailer, ledger, outbox.Config{Clock: clk}, zap.NewNop())
+	opts := scheduler.Options{
+		Concurrency: 1,
+		WorkerID:    "simulation",
+		History:     history,
+		Ledger:      ledger,
+		BaseURL:     "http://localhost:8080",
+		Clock:       clk,
+	}
+
+	// The virtual clock runs in UTC, so the ticks are the UTC top of the hour.
+	hourly, err := cron.ParseStandard("CRON_TZ=UTC " + scheduler.MailJobSpec)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	for _, ev := range timeline(cfg) {
+		clk.Set(ev.at)
+		if ev.restart {
+			missed, err := scheduler.CatchUp(ctx, history, ev.at, scheduler.CatchUpPolicy{
+				Schedule: hourly,
+				Grace:    cfg.CatchUpGrace,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(missed) == 0 {
+				continue
+			}
+		}
+		summary, err := scheduler.MailJob(ctx, subService, weatherService, opts, zap.NewNop())
+		if err != nil {
+			return nil, fmt.Errorf("mail job at %s: %w", ev.at.Format(time.RFC3339), err)
+		}
+		report.Runs = append(report.Runs, Run{At: ev.at, CatchUp: ev.restart, Summary: summary})
+
+		for {
+			n, err := dispatcher.DispatchOnce(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				break
+			}
+		}
+	}
+
+	report.Emails = mailer.sent
+	sort.SliceStable(report.Emails, func(i, j int) bool {
+		if !report.Emails[i].At.Equal(report.Emails[j].At) {
+			return report.Emails[i].At.Before(report.Emails[j].At)
+		}
+		return report.Emails[i].Recipient < report.Emails[j].Recipient
+	})
+	return report, nil
+}
+
+// timeline lists the hourly ticks the service is up for and the restarts at
+// the end of each outage, in order.
+func timeline(cfg Config) []event {
+	end := cfg.Start.Add(cfg.Duration)
+	tick := cfg.Start.Truncate(time.Hour)
+	if tick.Before(cfg.Start) {
+		tick = tick.Add(time.Hour)
+	}
+
+	var events []event
+	for ; tick.Before(end); tick = tick.Add(time.Hour) {
+		if !down(cfg.Outages, tick) {
+			events = append(events, event{at: tick})
+		}
+	}
+	for _, o := range cfg.Outages {
+		if !o.To.Before(cfg.Start) && o.To.Before(end) {
+			events = append(events, event{at: o.To, restart: true})
+		}
+	}
+	// A restart exactly on a tick comes after the tick's own run.
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return !events[i].restart && events[j].restart
+	})
+	return events
+}
+
+func down(outages []Outage, t time.Time) bool {
+	for _, o := range outages {
+		if !t.Before(o.From) && t.Before(o.To) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+// Package templates renders the emails the service sends from the HTML and
+// plain-text templates embedded in the binary. Templates write their text
+// through the t function, which translates it with the i18n catalogs.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+)
+
+const (
+	Confirmation  = "confirmation"
+	WeatherUpdate = "weather_update"
+	Alert         = "alert"
+	Goodbye       = "goodbye"
+
+	UnsubscribePage = "unsubscribe"
+)
+
+const (
+	UnsubscribeConfirm  = "confirm"
+	UnsubscribeDone     = "done"
+	UnsubscribeNotFound = "not_found"
+)
+
+//go:embed email/*.txt email/*.html page/*.html
+var files embed.FS
+
+// ConfirmationData fills the confirmation email.
+type ConfirmationData struct {
+	BaseURL string
+	Token   string
+}
+
+// WeatherUpdateData fills the scheduled weather email.
+type WeatherUpdateData struct {
+	BaseURL          string
+	City             string
+	Weather          model.Weather
+	UnsubscribeToken string
+}
+
+// AlertData fills an email about notable weather, with Reason saying what
+// triggered it.
+type AlertData struct {
+	BaseURL          string
+	City             string
+	Weather          model.Weather
+	Reason           string
+	UnsubscribeToken string
+}
+
+// GoodbyeData fills the email confirming an unsubscribe.
+type GoodbyeData struct {
+	BaseURL string
+	City    string
+}
+
+// UnsubscribePageData fills the page behind the unsubscribe link. Action is
+// the URL the confirmation form posts to.
+type UnsubscribePageData struct {
+	Lang   string
+	State  string
+	Action string
+}
+
+// Email is a rendered email. The text template of each email defines its
+// subject. ListUnsubscribe, when set, is the one-click unsubscribe URL
+// advertised in the List-Unsubscribe header.
+type Email struct {
+	Subject         string
+	Text            string
+	HTML            string
+	ListUnsubscribe string
+}
+
+type pair struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var (
+	registry = map[string]pair{}
+	pages    = map[string]*htmltemplate.Template{}
+)
+
+func translator(lang string) func(string, ...any) string {
+	return func(msg string, args ...any) string {
+		return i18n.T(lang, msg, args...)
+	}
+}
+
+func init() {
+	funcs := map[string]any{"t": translator(i18n.Default)}
+	for _, name := range []string{Confirmation, WeatherUpdate, Alert, Goodbye} {
+		registry[name] = pair{
+			text: texttemplate.Must(texttemplate.New(name+".txt").Funcs(funcs).Option("missingkey=error").ParseFS(files, "email/"+name+".txt")),
+			html: htmltemplate.Must(htmltemplate.New(name+".html").Funcs(funcs).Option("missingkey=error").ParseFS(files, "email/"+name+".html")),
+		}
+	}
+	pages[UnsubscribePage] = htmltemplate.Must(htmltemplate.New(UnsubscribePage+".html").Funcs(funcs).Option("missingkey=error").ParseFS(files, "page/"+UnsubscribePage+".html"))
+}
+
+// RenderPage executes the named HTML page template with data in lang.
+func RenderPage(lang, name string, data any) ([]byte, error) {
+	page, ok := pages[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown page template %q", name)
+	}
+	tmpl, err := page.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(map[string]any{"t": translator(i18n.Normalize(lang))})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render %s page: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Render executes the templates of the named email with data in lang. An
+// unsupported lang renders in i18n.Default.
+func Render(lang, name string, data any) (*Email, error) {
+	p, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	funcs := map[string]any{"t": translator(i18n.Normalize(lang))}
+	textTmpl, err := p.text.Clone()
+	if err != nil {
+		return nil, err
+	}
+	htmlTmpl, err := p.html.Clone()
+	if err != nil {
+		return nil, err
+	}
+	textTmpl.Funcs(funcs)
+	htmlTmpl.Funcs(funcs)
+
+	var subject, text, html bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := textTmpl.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := htmlTmpl.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", name, err)
+	}
+	return &Email{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
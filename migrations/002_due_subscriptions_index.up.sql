@@ -0,0 +1 @@
+CREATE INDEX idx_subscriptions_due ON subscriptions(id) INCLUDE (frequency, last_sent_at) WHERE confirmed;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_subscriptions_due;
+CREATE INDEX idx_subscriptions_due ON subscriptions(id) INCLUDE (frequency, last_sent_at) WHERE confirmed;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS claimed_until,
+    DROP COLUMN IF EXISTS claimed_by;
@@ -0,0 +1,6 @@
+ALTER TABLE subscriptions
+    ADD COLUMN claimed_by VARCHAR(255) NULL,
+    ADD COLUMN claimed_until TIMESTAMP WITH TIME ZONE NULL;
+
+DROP INDEX IF EXISTS idx_subscriptions_due;
+CREATE INDEX idx_subscriptions_due ON subscriptions(id) INCLUDE (frequency, last_sent_at, claimed_until) WHERE confirmed;
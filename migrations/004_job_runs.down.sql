@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS job_deliveries;
+DROP TABLE IF EXISTS job_runs;
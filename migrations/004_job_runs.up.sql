@@ -0,0 +1,26 @@
+CREATE TABLE job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    worker_id VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NULL,
+    total INTEGER NOT NULL DEFAULT 0,
+    sent INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE job_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES job_runs(id) ON DELETE CASCADE,
+    subscription_id BIGINT NOT NULL,
+    city VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    reason VARCHAR(64) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_job_deliveries_run_id ON job_deliveries(run_id, id);
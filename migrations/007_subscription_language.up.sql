@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions
+    ADD COLUMN language VARCHAR(8) NOT NULL DEFAULT 'en';
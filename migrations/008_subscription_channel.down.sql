@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS destination,
+    DROP COLUMN IF EXISTS channel;
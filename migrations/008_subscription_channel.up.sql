@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    ADD COLUMN channel VARCHAR(16) NOT NULL DEFAULT 'email',
+    ADD COLUMN destination VARCHAR(512) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions
+    ADD COLUMN webhook_secret VARCHAR(128) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS undeliverable_reason,
+    DROP COLUMN IF EXISTS undeliverable_at;
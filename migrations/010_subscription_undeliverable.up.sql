@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    ADD COLUMN undeliverable_at TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN undeliverable_reason VARCHAR(512) NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+CREATE TABLE suppressions (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    reason VARCHAR(16) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    source VARCHAR(32) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT uq_suppressions_email UNIQUE (email)
+);
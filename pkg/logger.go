@@ -1,15 +1,196 @@
 package pkg
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/redact"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-var Logger *zap.Logger
+const RootComponent = "root"
+
+type LogConfig struct {
+	Level    string
+	Format   string
+	Sampling bool
+	// Output defaults to stderr.
+	Output zapcore.WriteSyncer
+	// ComponentLevels overrides Level for named components, e.g. "repo": "warn".
+	ComponentLevels map[string]string
+}
+
+// Logging builds component loggers that share one encoder and sink but have
+// their own atomic level, so verbosity can be tuned per component at runtime.
+type Logging struct {
+	encoder  zapcore.Encoder
+	sink     zapcore.WriteSyncer
+	sampling bool
+
+	mu        sync.Mutex
+	root      zap.AtomicLevel
+	levels    map[string]zap.AtomicLevel
+	overrides map[string]bool
+}
+
+func NewLogging(cfg LogConfig) (*Logging, error) {
+	root, err := zap.ParseAtomicLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+
+	var encoder zapcore.Encoder
+	switch cfg.Format {
+	case "", "json":
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	case "console":
+		encCfg := zap.NewDevelopmentEncoderConfig()
+		encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewConsoleEncoder(encCfg)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+
+	sink := cfg.Output
+	if sink == nil {
+		sink = os.Stderr
+	}
+	l := &Logging{
+		encoder:   encoder,
+		sink:      zapcore.Lock(sink),
+		sampling:  cfg.Sampling,
+		root:      root,
+		levels:    make(map[string]zap.AtomicLevel),
+		overrides: make(map[string]bool),
+	}
+	for component, level := range cfg.ComponentLevels {
+		if err := l.SetLevel(component, level); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
 
-func InitLogger() {
-	var err error
-	Logger, err = zap.NewProduction()
+// NewBootstrapLogger is used before configuration is loaded.
+func NewBootstrapLogger() *zap.Logger {
+	logger, err := zap.NewProduction(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return redact.NewCore(c)
+	}))
 	if err != nil {
 		panic("cannot initialize zap logger: " + err.Error())
 	}
+	return logger
+}
+
+func (l *Logging) Logger(component string) *zap.Logger {
+	// Redaction sits inside the sampler: its Check admits every enabled entry,
+	// so wrapping it around the sampler would bypass sampling.
+	core := redact.NewCore(zapcore.NewCore(l.encoder.Clone(), l.sink, l.level(component)))
+	if l.sampling {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	}
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)).
+		Named(component)
+}
+
+func (l *Logging) level(component string) zap.AtomicLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lvl, ok := l.levels[component]; ok {
+		return lvl
+	}
+	lvl := zap.NewAtomicLevelAt(l.root.Level())
+	l.levels[component] = lvl
+	return lvl
+}
+
+// SetLevel changes the level of one component, or of the root and every
+// component without an explicit override when component is RootComponent.
+func (l *Logging) SetLevel(component, level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	if component == "" || component == RootComponent {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.root.SetLevel(parsed)
+		for name, lvl := range l.levels {
+			if !l.overrides[name] {
+				lvl.SetLevel(parsed)
+			}
+		}
+		return nil
+	}
+
+	lvl := l.level(component)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lvl.SetLevel(parsed)
+	l.overrides[component] = true
+	return nil
+}
+
+func (l *Logging) Levels() map[string]string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := map[string]string{RootComponent: l.root.String()}
+	for name, lvl := range l.levels {
+		out[name] = lvl.String()
+	}
+	return out
+}
+
+func (l *Logging) Sync() error {
+	return l.sink.Sync()
+}
+
+// ParseComponentLevels parses "repo=warn,scheduler=debug".
+func ParseComponentLevels(raw string) (map[string]string, error) {
+	out := make(map[string]string)
+	if strings.TrimSpace(raw) == "" {
+		return out, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		name, level, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" || level == "" {
+			return nil, fmt.Errorf("invalid component level %q", pair)
+		}
+		out[strings.TrimSpace(name)] = strings.TrimSpace(level)
+	}
+	return out, nil
+}
+
+type fieldsKey struct{}
+
+// ContextWithFields attaches request-scoped fields (request ID, trace ID) that
+// FromContext adds to whichever component logger handles the request.
+func ContextWithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if existing, ok := ctx.Value(fieldsKey{}).([]zap.Field); ok {
+		fields = append(append([]zap.Field{}, existing...), fields...)
+	}
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+func FromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	logger = OrNop(logger)
+	if ctx == nil {
+		return logger
+	}
+	if fields, ok := ctx.Value(fieldsKey{}).([]zap.Field); ok && len(fields) > 0 {
+		return logger.With(fields...)
+	}
+	return logger
+}
+
+func OrNop(logger *zap.Logger) *zap.Logger {
+	if logger == nil {
+		return zap.NewNop()
+	}
+	return logger
 }
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.uber.org/zap"
+)
+
+// AccessLog replaces gin's text logger with one structured zap line per
+// request. It must run after RequestID to pick up the request ID.
+func AccessLog(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("route", c.FullPath()),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("user_agent", c.Request.UserAgent()),
+			zap.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		logger := pkg.FromContext(c.Request.Context(), logger)
+		switch {
+		case status >= 500:
+			logger.Error("request", fields...)
+		case status >= 400:
+			logger.Warn("request", fields...)
+		default:
+			logger.Info("request", fields...)
+		}
+	}
+}
+
+// Recovery turns panics into 500 responses and logs them through zap
+// instead of gin's plain-text writer.
+func Recovery(logger *zap.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		pkg.FromContext(c.Request.Context(), logger).Error("panic recovered",
+			zap.String("panic", fmt.Sprint(recovered)),
+			zap.Stack("stack"),
+		)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
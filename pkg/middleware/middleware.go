@@ -11,26 +11,27 @@ import (
 
 var UUIDRegex = regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[1-5][a-fA-F0-9]{3}-[89abAB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$`)
 
-func abortWithError(c *gin.Context, status int) {
-	entry := pkg.Logger.With(zap.Int("status", status))
+func abortWithError(c *gin.Context, logger *zap.Logger, status int) {
+	entry := logger.With(zap.Int("status", status))
 	entry.Warn("invalid token format")
 
 	c.Status(status)
 	c.Abort()
 }
 
-func TokenUUIDRequiredMiddleware(paramName string, errMsg string) gin.HandlerFunc {
+func TokenUUIDRequiredMiddleware(paramName string, errMsg string, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.Param(paramName)
-		entry := pkg.Logger.With(zap.String("token", token))
+		logger := pkg.FromContext(c.Request.Context(), logger)
+		entry := logger.With(zap.String("token", token))
 
 		if token == "" || !UUIDRegex.MatchString(token) {
 			entry.Warn("invalid or missing token")
-			abortWithError(c, http.StatusBadRequest)
+			abortWithError(c, logger, http.StatusBadRequest)
 			return
 		}
 
-		entry.Info("valid token")
+		entry.Debug("valid token")
 		c.Set(paramName, token)
 		c.Next()
 	}
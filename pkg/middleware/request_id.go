@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDKey    = "request_id"
+)
+
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID accepts a well-formed incoming X-Request-ID or generates one,
+// echoes it back and attaches it to the request context so every component
+// logger handling the request includes it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDRegex.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := c.Request.Context()
+		fields := []zap.Field{zap.String(RequestIDKey, id)}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(pkg.ContextWithFields(ctx, fields...))
+		c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package redact
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var secretKeys = map[string]bool{
+	"password": true,
+	"pass":     true,
+	"secret":   true,
+	"api_key":  true,
+	"apikey":   true,
+	"key":      true,
+}
+
+var emailKeys = map[string]bool{
+	"email": true,
+	"to":    true,
+	"from":  true,
+}
+
+type core struct {
+	zapcore.Core
+}
+
+// NewCore wraps a zap core so every message and field is scrubbed before it
+// is encoded, regardless of which package produced the log line.
+func NewCore(c zapcore.Core) zapcore.Core {
+	return &core{Core: c}
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(Fields(fields))}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = String(ent.Message)
+	return c.Core.Write(ent, Fields(fields))
+}
+
+func Fields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field(f)
+	}
+	return out
+}
+
+func Field(f zapcore.Field) zapcore.Field {
+	key := strings.ToLower(f.Key)
+	switch f.Type {
+	case zapcore.StringType:
+		switch {
+		case secretKeys[key]:
+			f.String = Redacted
+		case emailKeys[key]:
+			f.String = Email(f.String)
+		case strings.Contains(key, "token"):
+			f.String = Token(f.String)
+		default:
+			f.String = String(f.String)
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: String(err.Error())}
+		}
+	case zapcore.StringerType:
+		if st, ok := f.Interface.(fmt.Stringer); ok && st != nil {
+			return Field(zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: st.String()})
+		}
+	case zapcore.ReflectType:
+		if secretKeys[key] {
+			return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: Redacted}
+		}
+	}
+	return f
+}
@@ -0,0 +1,45 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+const Redacted = "[REDACTED]"
+
+var (
+	emailRegex  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	uuidRegex   = regexp.MustCompile(`[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}`)
+	secretParam = regexp.MustCompile(`(?i)\b(key|api_key|apikey|token|secret|password|access_token)=([^&\s"']+)`)
+)
+
+// Email keeps the first character of the local part and the domain, so log
+// lines stay correlatable without exposing the address.
+func Email(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return Redacted
+	}
+	return local[:1] + "***@" + domain
+}
+
+// Token keeps a short prefix of a token so it can still be matched against
+// a database row by someone who already has access to it.
+func Token(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return token[:4] + "****"
+}
+
+// String scrubs emails, UUID tokens and secret query parameters out of free
+// text such as log messages, URLs and error strings.
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	s = secretParam.ReplaceAllString(s, "${1}="+Redacted)
+	s = emailRegex.ReplaceAllStringFunc(s, Email)
+	s = uuidRegex.ReplaceAllStringFunc(s, Token)
+	return s
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
-	"github.com/l4ndm1nes/Weather-API-Application/pkg"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,18 +21,23 @@ import (
 	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
 
 type dummyMailer struct{}
 
-func (d *dummyMailer) SendConfirmation(email, token string) error              { return nil }
-func (d *dummyMailer) SendWeatherUpdate(email, city, weatherInfo string) error { return nil }
+func (d *dummyMailer) SendConfirmation(ctx context.Context, email, token, lang string) error {
+	return nil
+}
+func (d *dummyMailer) SendWeatherUpdate(ctx context.Context, email string, update model.WeatherUpdate) error {
+	return nil
+}
 
 type dummyWeatherProvider struct{}
 
-func (d *dummyWeatherProvider) GetWeather(city string) (*model.Weather, error) {
+func (d *dummyWeatherProvider) GetWeather(ctx context.Context, city string) (*model.Weather, error) {
 	if city == "Kyiv" {
 		return &model.Weather{
 			Temperature: 21.5,
@@ -44,10 +48,6 @@ func (d *dummyWeatherProvider) GetWeather(city string) (*model.Weather, error) {
 	return nil, fmt.Errorf("city not found")
 }
 
-func init() {
-	pkg.Logger = zap.NewNop()
-}
-
 func setupPostgresContainer(ctx context.Context, t *testing.T) (testcontainers.Container, string) {
 	t.Helper()
 	req := testcontainers.ContainerRequest{
@@ -87,13 +87,13 @@ func TestSubscribe_Integration(t *testing.T) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	assert.NoError(t, err)
-	err = db.AutoMigrate(&repo.SubscriptionDB{})
+	err = db.AutoMigrate(&repo.SubscriptionDB{}, &repo.SuppressionDB{})
 	assert.NoError(t, err)
 
-	subscriptionRepo := repo.NewPostgresRepo(db)
+	subscriptionRepo := repo.NewPostgresRepo(db, zap.NewNop())
 	mailer := &dummyMailer{}
-	subService := service.NewSubscriptionService(subscriptionRepo, mailer)
-	subHandler := handler.NewSubscriptionHandler(subService, nil)
+	subService := service.NewSubscriptionService(subscriptionRepo, mailer, zap.NewNop())
+	subHandler := handler.NewSubscriptionHandler(subService, nil, zap.NewNop())
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
@@ -158,15 +158,15 @@ func TestSubscribe_Integration(t *testing.T) {
 func TestConfirmSubscription_Integration(t *testing.T) {
 	mockRepo := &mocks.SubscriptionRepository{}
 	mailer := &dummyMailer{}
-	subService := service.NewSubscriptionService(mockRepo, mailer)
-	subHandler := handler.NewSubscriptionHandler(subService, nil)
+	subService := service.NewSubscriptionService(mockRepo, mailer, zap.NewNop())
+	subHandler := handler.NewSubscriptionHandler(subService, nil, zap.NewNop())
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
-	r.GET("/api/confirm/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token"), subHandler.ConfirmSubscription)
+	r.GET("/api/confirm/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", zap.NewNop()), subHandler.ConfirmSubscription)
 
 	validToken := "550e8400-e29b-41d4-a716-446655440000"
-	mockRepo.On("GetByToken", validToken).Return(&model.Subscription{
+	mockRepo.On("GetByToken", mock.Anything, validToken).Return(&model.Subscription{
 		Email:        "confirmtest@email.com",
 		City:         "Kyiv",
 		Frequency:    "daily",
@@ -175,7 +175,7 @@ func TestConfirmSubscription_Integration(t *testing.T) {
 	}, nil)
 
 	alreadyToken := "123e4567-e89b-12d3-a456-426614174000"
-	mockRepo.On("GetByToken", alreadyToken).Return(&model.Subscription{
+	mockRepo.On("GetByToken", mock.Anything, alreadyToken).Return(&model.Subscription{
 		Email:        "already@email.com",
 		City:         "Lviv",
 		Frequency:    "daily",
@@ -184,9 +184,9 @@ func TestConfirmSubscription_Integration(t *testing.T) {
 	}, nil)
 
 	notFoundToken := "b472a266-d0bf-4ebd-94a8-6a9655cdd8b3"
-	mockRepo.On("GetByToken", notFoundToken).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetByToken", mock.Anything, notFoundToken).Return(nil, gorm.ErrRecordNotFound)
 
-	mockRepo.On("Update", mock.Anything).Return(nil) // Это заглушка, которая будет использоваться, когда вызывается Update
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil) // Это заглушка, которая будет использоваться, когда вызывается Update
 
 	tests := []struct {
 		name       string
@@ -244,12 +244,12 @@ func TestUnsubscribe_Integration(t *testing.T) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	assert.NoError(t, err)
-	assert.NoError(t, db.AutoMigrate(&repo.SubscriptionDB{}))
+	assert.NoError(t, db.AutoMigrate(&repo.SubscriptionDB{}, &repo.SuppressionDB{}))
 
-	subscriptionRepo := repo.NewPostgresRepo(db)
+	subscriptionRepo := repo.NewPostgresRepo(db, zap.NewNop())
 	mailer := &dummyMailer{}
-	subService := service.NewSubscriptionService(subscriptionRepo, mailer)
-	subHandler := handler.NewSubscriptionHandler(subService, nil)
+	subService := service.NewSubscriptionService(subscriptionRepo, mailer, zap.NewNop())
+	subHandler := handler.NewSubscriptionHandler(subService, nil, zap.NewNop())
 
 	unsubToken := "ae7b31ab-7b5b-4be0-8f89-7e0a9c872f0d"
 	sub := &repo.SubscriptionDB{
@@ -260,14 +260,23 @@ func TestUnsubscribe_Integration(t *testing.T) {
 		Confirmed:        true,
 	}
 	assert.NoError(t, db.Create(sub).Error)
+	oneClickToken := "5b0f4f4e-3c0e-4d43-9a8b-7f0e0a6f2c11"
+	assert.NoError(t, db.Create(&repo.SubscriptionDB{
+		Email:            "one-click@email.com",
+		City:             "Kyiv",
+		Frequency:        "daily",
+		UnsubscribeToken: oneClickToken,
+		Confirmed:        true,
+	}).Error)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
-	r.GET("/api/unsubscribe/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token"), subHandler.Unsubscribe)
+	r.POST("/api/unsubscribe/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", zap.NewNop()), subHandler.Unsubscribe)
 
 	tests := []struct {
 		name       string
 		token      string
+		body       string
 		wantStatus int
 	}{
 		{
@@ -275,6 +284,12 @@ func TestUnsubscribe_Integration(t *testing.T) {
 			token:      unsubToken,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "RFC 8058 one-click",
+			token:      oneClickToken,
+			body:       "List-Unsubscribe=One-Click",
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:       "token not found",
 			token:      "dfc16b26-842a-4c8e-b31c-53c6a29360e6",
@@ -290,7 +305,10 @@ func TestUnsubscribe_Integration(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			url := "/api/unsubscribe/" + tc.token
-			req := httptest.NewRequest("GET", url, nil)
+			req := httptest.NewRequest("POST", url, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
 
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
@@ -311,14 +329,14 @@ func TestGetWeather_Integration(t *testing.T) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	assert.NoError(t, err)
-	assert.NoError(t, db.AutoMigrate(&repo.SubscriptionDB{}))
+	assert.NoError(t, db.AutoMigrate(&repo.SubscriptionDB{}, &repo.SuppressionDB{}))
 
-	subscriptionRepo := repo.NewPostgresRepo(db)
+	subscriptionRepo := repo.NewPostgresRepo(db, zap.NewNop())
 	mailer := &dummyMailer{}
 	weatherProvider := &dummyWeatherProvider{}
-	subService := service.NewSubscriptionService(subscriptionRepo, mailer)
+	subService := service.NewSubscriptionService(subscriptionRepo, mailer, zap.NewNop())
 	weatherService := service.NewWeatherService(weatherProvider)
-	subHandler := handler.NewSubscriptionHandler(subService, weatherService)
+	subHandler := handler.NewSubscriptionHandler(subService, weatherService, zap.NewNop())
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
@@ -0,0 +1,293 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/repo"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListDue_Integration(t *testing.T) {
+	ctx := context.Background()
+	pgC, dsn := setupPostgresContainer(ctx, t)
+	defer func() {
+		if err := pgC.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %v", err)
+		}
+	}()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&repo.SubscriptionDB{}, &repo.SuppressionDB{}, &repo.OutboxMessageDB{}))
+	subscriptionRepo := repo.NewPostgresRepo(db, zap.NewNop())
+
+	now := time.Now().UTC().Truncate(time.Second)
+	hourAgo := now.Add(-time.Hour)
+	recent := now.Add(-2 * time.Hour)
+	seeds := []struct {
+		frequency  string
+		confirmed  bool
+		lastSentAt *time.Time
+	}{
+		{"hourly", true, nil},
+		{"hourly", true, &hourAgo},
+		{"hourly", false, nil},
+		{"daily", true, nil},
+		{"daily", true, &recent},
+		{"daily", true, &hourAgo},
+		{"hourly", true, &now},
+	}
+	for i, s := range seeds {
+		assert.NoError(t, subscriptionRepo.Create(ctx, &model.Subscription{
+			Email:            fmt.Sprintf("due%d@example.com", i),
+			City:             "Kyiv",
+			Frequency:        s.frequency,
+			Confirmed:        s.confirmed,
+			ConfirmToken:     fmt.Sprintf("confirm-%d", i),
+			UnsubscribeToken: fmt.Sprintf("unsub-%d", i),
+			LastSentAt:       s.lastSentAt,
+		}))
+	}
+
+	q := service.DueQuery{
+		HourlySentBefore: now,
+		DailySentBefore:  now.Add(-23 * time.Hour),
+		Limit:            2,
+	}
+	var emails []string
+	for {
+		page, err := subscriptionRepo.ListDue(ctx, q)
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, sub := range page {
+			emails = append(emails, sub.Email)
+		}
+		q.AfterID = page[len(page)-1].ID
+	}
+
+	assert.Equal(t, []string{"due0@example.com", "due1@example.com", "due3@example.com"}, emails)
+
+	q = service.DueQuery{
+		HourlySentBefore: now,
+		DailySentBefore:  now.Add(-23 * time.Hour),
+		Limit:            10,
+		Scope:            service.Scope{City: " kyiv "},
+	}
+	scoped, err := subscriptionRepo.ListDue(ctx, q)
+	assert.NoError(t, err)
+	assert.Len(t, scoped, 3)
+
+	q.Scope = service.Scope{City: "Lviv"}
+	scoped, err = subscriptionRepo.ListDue(ctx, q)
+	assert.NoError(t, err)
+	assert.Empty(t, scoped)
+
+	due3, err := subscriptionRepo.FindByEmail(ctx, "due3@example.com")
+	assert.NoError(t, err)
+	q.Scope = service.Scope{SubscriptionID: due3.ID}
+	scoped, err = subscriptionRepo.ListDue(ctx, q)
+	assert.NoError(t, err)
+	assert.Len(t, scoped, 1)
+
+	assert.NoError(t, subscriptionRepo.MarkUndeliverable(ctx, "due3@example.com", "550 5.1.1 mailbox unavailable", now))
+	scoped, err = subscriptionRepo.ListDue(ctx, q)
+	assert.NoError(t, err)
+	assert.Empty(t, scoped)
+
+	// due3 was loaded before it was marked; saving it must not revive it.
+	due3.LastSentAt = &now
+	assert.NoError(t, subscriptionRepo.UpdateWithOutbox(ctx, due3, &model.OutboxMessage{Kind: model.OutboxWeatherUpdate, Recipient: due3.Email}))
+	assert.NoError(t, subscriptionRepo.Update(ctx, due3))
+	due3, err = subscriptionRepo.FindByEmail(ctx, "due3@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "550 5.1.1 mailbox unavailable", due3.UndeliverableReason)
+	assert.NotNil(t, due3.UndeliverableAt)
+	assert.Equal(t, now, due3.LastSentAt.UTC())
+
+	suppressions := repo.NewPostgresSuppressionRepo(db, zap.NewNop())
+	assert.NoError(t, suppressions.Suppress(ctx, &model.Suppression{Email: " Due1@Example.com", Reason: model.EmailEventBounce, Source: "generic"}))
+	suppressed, err := suppressions.IsSuppressed(ctx, "due1@example.com")
+	assert.NoError(t, err)
+	assert.True(t, suppressed)
+	q.Scope = service.Scope{}
+	scoped, err = subscriptionRepo.ListDue(ctx, q)
+	assert.NoError(t, err)
+	assert.Len(t, scoped, 1)
+	assert.Equal(t, "due0@example.com", scoped[0].Email)
+}
+
+func TestClaimDue_Integration(t *testing.T) {
+	ctx := context.Background()
+	pgC, dsn := setupPostgresContainer(ctx, t)
+	defer func() {
+		if err := pgC.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %v", err)
+		}
+	}()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&repo.SubscriptionDB{}, &repo.SuppressionDB{}))
+	subscriptionRepo := repo.NewPostgresRepo(db, zap.NewNop())
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, subscriptionRepo.Create(ctx, &model.Subscription{
+			Email:            fmt.Sprintf("claim%d@example.com", i),
+			City:             "Kyiv",
+			Frequency:        "hourly",
+			Confirmed:        true,
+			ConfirmToken:     fmt.Sprintf("confirm-%d", i),
+			UnsubscribeToken: fmt.Sprintf("unsub-%d", i),
+		}))
+	}
+
+	now := time.Now().UTC()
+	claim := func(owner string) []*model.Subscription {
+		subs, err := subscriptionRepo.ClaimDue(ctx, service.ClaimQuery{
+			DueQuery: service.DueQuery{HourlySentBefore: now, DailySentBefore: now, Limit: 3},
+			Owner:    owner,
+			Now:      now,
+			Until:    now.Add(time.Minute),
+		})
+		assert.NoError(t, err)
+		return subs
+	}
+
+	first := claim("worker-1")
+	second := claim("worker-2")
+	assert.Len(t, first, 3)
+	assert.Len(t, second, 2)
+	assert.Empty(t, claim("worker-3"))
+
+	seen := map[int64]bool{}
+	for _, sub := range append(first, second...) {
+		assert.False(t, seen[sub.ID])
+		seen[sub.ID] = true
+	}
+
+	locker := repo.NewAdvisoryLocker(db, zap.NewNop())
+	unlock, acquired, err := locker.TryLock(ctx, "mail_job")
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	_, acquired, err = locker.TryLock(ctx, "mail_job")
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+	unlock()
+	unlock, acquired, err = locker.TryLock(ctx, "mail_job")
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	unlock()
+}
+
+func TestDeliveryLedger_Integration(t *testing.T) {
+	ctx := context.Background()
+	pgC, dsn := setupPostgresContainer(ctx, t)
+	defer func() {
+		if err := pgC.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %v", err)
+		}
+	}()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&repo.DeliveryDB{}))
+	ledger := repo.NewPostgresDeliveryRepo(db, zap.NewNop())
+
+	period := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	entry, reserved, err := ledger.Reserve(ctx, 1, period)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, model.DeliveryPending, entry.Status)
+
+	_, reserved, err = ledger.Reserve(ctx, 1, period)
+	assert.NoError(t, err)
+	assert.False(t, reserved, "pending period must not be reserved twice")
+
+	assert.NoError(t, ledger.MarkFailed(ctx, entry.ID, "smtp down"))
+	retry, reserved, err := ledger.Reserve(ctx, 1, period)
+	assert.NoError(t, err)
+	assert.True(t, reserved, "failed period can be retried")
+	assert.Equal(t, entry.ID, retry.ID)
+	assert.Equal(t, 2, retry.Attempts)
+
+	assert.NoError(t, ledger.MarkSent(ctx, retry.ID, time.Now()))
+	_, reserved, err = ledger.Reserve(ctx, 1, period)
+	assert.NoError(t, err)
+	assert.False(t, reserved, "sent period is final")
+
+	_, reserved, err = ledger.Reserve(ctx, 1, period.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, reserved, "next period is independent")
+}
+
+func TestOutbox_Integration(t *testing.T) {
+	ctx := context.Background()
+	pgC, dsn := setupPostgresContainer(ctx, t)
+	defer func() {
+		if err := pgC.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate container: %v", err)
+		}
+	}()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&repo.SubscriptionDB{}, &repo.SuppressionDB{}, &repo.OutboxMessageDB{}))
+	subscriptionRepo := repo.NewPostgresRepo(db, zap.NewNop())
+	outboxRepo := repo.NewPostgresOutboxRepo(db, zap.NewNop())
+
+	sub := &model.Subscription{
+		Email:            "outbox@example.com",
+		City:             "Kyiv",
+		Frequency:        "daily",
+		ConfirmToken:     "confirm-outbox",
+		UnsubscribeToken: "unsub-outbox",
+	}
+	msg := &model.OutboxMessage{
+		Kind:      model.OutboxConfirmation,
+		Recipient: sub.Email,
+		Payload:   model.OutboxPayload{Token: sub.ConfirmToken},
+	}
+	assert.NoError(t, subscriptionRepo.CreateWithOutbox(ctx, sub, msg))
+	assert.NotZero(t, msg.ID)
+
+	rolledBack := &model.Subscription{Email: "rollback@example.com", City: "Lviv", Frequency: "daily", ConfirmToken: "c2", UnsubscribeToken: "u2"}
+	badMsg := &model.OutboxMessage{Kind: strings.Repeat("x", 64), Recipient: rolledBack.Email}
+	assert.Error(t, subscriptionRepo.CreateWithOutbox(ctx, rolledBack, badMsg))
+	_, err = subscriptionRepo.FindByEmail(ctx, rolledBack.Email)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "a failed outbox insert must roll back the subscription")
+
+	now := time.Now()
+	claimed, err := outboxRepo.ClaimDue(ctx, now, 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, "confirm-outbox", claimed[0].Payload.Token)
+	again, err := outboxRepo.ClaimDue(ctx, now, 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, again, "claimed messages stay leased")
+
+	_, err = outboxRepo.Replay(ctx, msg.ID)
+	assert.ErrorIs(t, err, outbox.ErrNotReplayable)
+	assert.NoError(t, outboxRepo.MarkDead(ctx, msg.ID, 8, "550 no such user"))
+	dead, err := outboxRepo.ListMessages(ctx, model.OutboxDead, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, dead, 1)
+
+	replayed, err := outboxRepo.Replay(ctx, msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.OutboxPending, replayed.Status)
+	assert.Equal(t, 0, replayed.Attempts)
+
+	_, err = outboxRepo.Replay(ctx, msg.ID+100)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/captcha"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/formtoken"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestFormTokenSigner_Verify(t *testing.T) {
+	signer, err := formtoken.NewSigner([]byte("secret"))
+	assert.NoError(t, err)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: signer.Issue(now.Add(-10 * time.Second)), wantErr: nil},
+		{name: "too fast", token: signer.Issue(now.Add(-1 * time.Second)), wantErr: formtoken.ErrTooFast},
+		{name: "expired", token: signer.Issue(now.Add(-2 * time.Hour)), wantErr: formtoken.ErrExpired},
+		{name: "garbage", token: "not-a-token", wantErr: formtoken.ErrInvalid},
+		{name: "tampered", token: "1." + signer.Issue(now)[len("1."):], wantErr: formtoken.ErrInvalid},
+		{name: "empty", token: "", wantErr: formtoken.ErrInvalid},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := signer.Verify(tc.token, now, 3*time.Second, time.Hour)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestSubscriptionHandler_Subscribe_BotGuard(t *testing.T) {
+	signer, err := formtoken.NewSigner([]byte("secret"))
+	assert.NoError(t, err)
+	oldToken := signer.Issue(time.Now().Add(-time.Minute))
+	freshToken := signer.Issue(time.Now())
+
+	tests := []struct {
+		name        string
+		body        gin.H
+		wantCalled  bool
+		wantStatus  int
+		withCaptcha bool
+	}{
+		{
+			name:       "passes",
+			body:       gin.H{"form_token": oldToken},
+			wantCalled: true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "honeypot filled is silently dropped",
+			body:       gin.H{"form_token": oldToken, "website": "http://spam.example"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing form token",
+			body:       gin.H{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "submitted too fast",
+			body:       gin.H{"form_token": freshToken},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "captcha passes",
+			body:        gin.H{"form_token": oldToken, "captcha_token": "ok"},
+			withCaptcha: true,
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "captcha rejected",
+			body:        gin.H{"form_token": oldToken, "captcha_token": "bad"},
+			withCaptcha: true,
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			subMock := &mocks.SubscriptionService{}
+			if tc.wantCalled {
+				subMock.On("Subscribe", mock.Anything, mock.Anything).Return(&model.Subscription{}, nil).Once()
+			}
+			h := handler.NewSubscriptionHandler(subMock, &mocks.WeatherService{}, zap.NewNop())
+			h.BotGuard = handler.NewBotGuard(signer, 3*time.Second, time.Hour)
+			if tc.withCaptcha {
+				h.BotGuard.WithCaptcha("turnstile", "site-key", &captcha.FakeVerifier{ValidToken: "ok"})
+			}
+
+			r := gin.New()
+			r.POST("/subscribe", h.Subscribe)
+
+			payload := gin.H{"email": "bot@email.com", "city": "Kyiv", "frequency": "daily"}
+			for k, v := range tc.body {
+				payload[k] = v
+			}
+			body, _ := json.Marshal(payload)
+			req := httptest.NewRequest(http.MethodPost, "/subscribe", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			subMock.AssertExpectations(t)
+			if !tc.wantCalled {
+				subMock.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -4,17 +4,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
 	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -33,7 +37,7 @@ func TestSubscriptionHandler_Subscribe(t *testing.T) {
 				"frequency": "daily",
 			},
 			mockSetup: func(svc *mocks.SubscriptionService) {
-				svc.On("Subscribe", mock.Anything).Return(&model.Subscription{
+				svc.On("Subscribe", mock.Anything, mock.Anything).Return(&model.Subscription{
 					Email:     "test@email.com",
 					City:      "Kyiv",
 					Frequency: "daily",
@@ -49,10 +53,21 @@ func TestSubscriptionHandler_Subscribe(t *testing.T) {
 				"frequency": "daily",
 			},
 			mockSetup: func(svc *mocks.SubscriptionService) {
-				svc.On("Subscribe", mock.Anything).Return(nil, errors.New("email already subscribed")).Once()
+				svc.On("Subscribe", mock.Anything, mock.Anything).Return(nil, errors.New("email already subscribed")).Once()
 			},
 			wantStatus: http.StatusConflict,
 		},
+		{
+			name: "unknown channel",
+			inputBody: gin.H{
+				"email":     "test@email.com",
+				"city":      "Kyiv",
+				"frequency": "daily",
+				"channel":   "pager",
+			},
+			mockSetup:  func(svc *mocks.SubscriptionService) {},
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name: "invalid input",
 			inputBody: gin.H{
@@ -69,7 +84,7 @@ func TestSubscriptionHandler_Subscribe(t *testing.T) {
 			subMock := &mocks.SubscriptionService{}
 			weatherMock := &mocks.WeatherService{}
 			tc.mockSetup(subMock)
-			h := handler.NewSubscriptionHandler(subMock, weatherMock)
+			h := handler.NewSubscriptionHandler(subMock, weatherMock, zap.NewNop())
 
 			r := gin.Default()
 			r.POST("/subscribe", h.Subscribe)
@@ -88,15 +103,15 @@ func TestSubscriptionHandler_Subscribe(t *testing.T) {
 
 func TestSubscriptionHandler_ConfirmSubscription(t *testing.T) {
 	mockRepo := &mocks.SubscriptionRepository{}
-	subService := service.NewSubscriptionService(mockRepo, nil) // Без mailer
-	subHandler := handler.NewSubscriptionHandler(subService, nil)
+	subService := service.NewSubscriptionService(mockRepo, nil, zap.NewNop()) // Без mailer
+	subHandler := handler.NewSubscriptionHandler(subService, nil, zap.NewNop())
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
-	r.GET("/api/confirm/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token"), subHandler.ConfirmSubscription)
+	r.GET("/api/confirm/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", zap.NewNop()), subHandler.ConfirmSubscription)
 
 	validToken := "550e8400-e29b-41d4-a716-446655440000"
-	mockRepo.On("GetByToken", validToken).Return(&model.Subscription{
+	mockRepo.On("GetByToken", mock.Anything, validToken).Return(&model.Subscription{
 		Email:        "confirmtest@email.com",
 		City:         "Kyiv",
 		Frequency:    "daily",
@@ -105,7 +120,7 @@ func TestSubscriptionHandler_ConfirmSubscription(t *testing.T) {
 	}, nil)
 
 	alreadyToken := "123e4567-e89b-12d3-a456-426614174000"
-	mockRepo.On("GetByToken", alreadyToken).Return(&model.Subscription{
+	mockRepo.On("GetByToken", mock.Anything, alreadyToken).Return(&model.Subscription{
 		Email:        "already@email.com",
 		City:         "Lviv",
 		Frequency:    "daily",
@@ -114,9 +129,9 @@ func TestSubscriptionHandler_ConfirmSubscription(t *testing.T) {
 	}, nil)
 
 	notFoundToken := "b472a266-d0bf-4ebd-94a8-6a9655cdd8b3"
-	mockRepo.On("GetByToken", notFoundToken).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetByToken", mock.Anything, notFoundToken).Return(nil, gorm.ErrRecordNotFound)
 
-	mockRepo.On("Update", mock.Anything).Return(nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
 
 	tests := []struct {
 		name       string
@@ -163,6 +178,23 @@ func TestSubscriptionHandler_ConfirmSubscription(t *testing.T) {
 	}
 }
 
+func TestSubscriptionHandler_ConfirmSubscription_WebhookVerificationFailed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	subMock := &mocks.SubscriptionService{}
+	subMock.On("ConfirmSubscription", mock.Anything, "550e8400-e29b-41d4-a716-446655440000").
+		Return(fmt.Errorf("%w: timeout", notify.ErrVerificationFailed)).Once()
+	subHandler := handler.NewSubscriptionHandler(subMock, nil, zap.NewNop())
+	r := gin.New()
+	r.GET("/api/confirm/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", zap.NewNop()), subHandler.ConfirmSubscription)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/confirm/550e8400-e29b-41d4-a716-446655440000", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Webhook verification failed")
+	subMock.AssertExpectations(t)
+}
+
 func TestSubscriptionHandler_GetWeather(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -174,7 +206,7 @@ func TestSubscriptionHandler_GetWeather(t *testing.T) {
 			name:      "success",
 			queryCity: "Kyiv",
 			mockSetup: func(ws *mocks.WeatherService) {
-				ws.On("GetWeather", "Kyiv").Return(&model.Weather{
+				ws.On("GetLocalizedWeather", mock.Anything, "Kyiv", "en").Return(&model.Weather{
 					Temperature: 20,
 					Humidity:    60,
 					Description: "Sunny",
@@ -186,7 +218,7 @@ func TestSubscriptionHandler_GetWeather(t *testing.T) {
 			name:      "city not found",
 			queryCity: "Atlantis",
 			mockSetup: func(ws *mocks.WeatherService) {
-				ws.On("GetWeather", "Atlantis").Return(nil, errors.New("City not found")).Once()
+				ws.On("GetLocalizedWeather", mock.Anything, "Atlantis", "en").Return(nil, errors.New("City not found")).Once()
 			},
 			wantStatus: http.StatusNotFound,
 		},
@@ -204,7 +236,7 @@ func TestSubscriptionHandler_GetWeather(t *testing.T) {
 			subMock := &mocks.SubscriptionService{}
 			weatherMock := &mocks.WeatherService{}
 			tc.mockSetup(weatherMock)
-			h := handler.NewSubscriptionHandler(subMock, weatherMock)
+			h := handler.NewSubscriptionHandler(subMock, weatherMock, zap.NewNop())
 
 			r := gin.Default()
 			r.GET("/weather", h.GetWeather)
@@ -228,7 +260,7 @@ func TestSubscriptionHandler_Unsubscribe(t *testing.T) {
 			name:  "success",
 			token: "5f2a17b1-110c-4881-bc19-41c3edaa0657",
 			mockSetup: func(svc *mocks.SubscriptionService) {
-				svc.On("Unsubscribe", "5f2a17b1-110c-4881-bc19-41c3edaa0657").Return(nil).Once()
+				svc.On("Unsubscribe", mock.Anything, "5f2a17b1-110c-4881-bc19-41c3edaa0657").Return(nil).Once()
 			},
 			wantStatus: http.StatusOK,
 		},
@@ -236,7 +268,7 @@ func TestSubscriptionHandler_Unsubscribe(t *testing.T) {
 			name:  "token not found",
 			token: "dfc16b26-842a-4c8e-b31c-53c6a29360e6",
 			mockSetup: func(svc *mocks.SubscriptionService) {
-				svc.On("Unsubscribe", "dfc16b26-842a-4c8e-b31c-53c6a29360e6").Return(gorm.ErrRecordNotFound).Once()
+				svc.On("Unsubscribe", mock.Anything, "dfc16b26-842a-4c8e-b31c-53c6a29360e6").Return(gorm.ErrRecordNotFound).Once()
 			},
 			wantStatus: http.StatusNotFound,
 		},
@@ -254,16 +286,47 @@ func TestSubscriptionHandler_Unsubscribe(t *testing.T) {
 			subMock := &mocks.SubscriptionService{}
 			weatherMock := &mocks.WeatherService{}
 			tc.mockSetup(subMock)
-			h := handler.NewSubscriptionHandler(subMock, weatherMock)
+			h := handler.NewSubscriptionHandler(subMock, weatherMock, zap.NewNop())
 
 			r := gin.Default()
-			r.GET("/unsubscribe/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token"), h.Unsubscribe)
+			r.POST("/unsubscribe/:token", middleware.TokenUUIDRequiredMiddleware("token", "Invalid token", zap.NewNop()), h.Unsubscribe)
 			url := "/unsubscribe/" + tc.token
-			req := httptest.NewRequest(http.MethodGet, url, nil)
+			req := httptest.NewRequest(http.MethodPost, url, strings.NewReader("List-Unsubscribe=One-Click"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 			w := httptest.NewRecorder()
 
 			r.ServeHTTP(w, req)
 			assert.Equal(t, tc.wantStatus, w.Code)
+			subMock.AssertExpectations(t)
 		})
 	}
 }
+
+func TestSubscriptionHandler_UnsubscribePage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	token := "5f2a17b1-110c-4881-bc19-41c3edaa0657"
+	subMock := &mocks.SubscriptionService{}
+	subMock.On("Unsubscribe", mock.Anything, token).Return(nil).Once()
+	r := gin.New()
+	handler.RegisterRoutes(r, handler.NewSubscriptionHandler(subMock, nil, zap.NewNop()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/unsubscribe/"+token, nil)
+	req.Header.Set("Accept-Language", "uk")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `<form method="post" action="/api/unsubscribe/`+token+`">`)
+	assert.Contains(t, w.Body.String(), "Відписатися від оновлень погоди?")
+	subMock.AssertNotCalled(t, "Unsubscribe", mock.Anything, mock.Anything)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/unsubscribe/"+token, nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "You have unsubscribed from weather updates")
+	subMock.AssertExpectations(t)
+}
@@ -0,0 +1,136 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/weatherapi"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/health"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubFailureCounter struct {
+	failures    int
+	reason      string
+	lastSuccess time.Time
+}
+
+func (s stubFailureCounter) ConsecutiveFailures() (int, string) { return s.failures, s.reason }
+
+func (s stubFailureCounter) LastCalls() (time.Time, time.Time) { return s.lastSuccess, time.Time{} }
+
+// The adapter must not import health, so the contract is pinned here.
+var _ health.FailureCounter = (*weatherapi.WeatherAPIProvider)(nil)
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	upCheck := func(ctx context.Context) health.Result { return health.Result{Status: health.StatusUp} }
+	downCheck := func(ctx context.Context) health.Result {
+		return health.Result{Status: health.StatusDown, Error: "connection refused"}
+	}
+
+	tests := []struct {
+		name       string
+		setup      func(r *health.Registry)
+		wantStatus int
+		wantReport health.Status
+	}{
+		{
+			name: "all up",
+			setup: func(r *health.Registry) {
+				r.Register("database", true, upCheck)
+				r.Register("weather_provider", false, upCheck)
+			},
+			wantStatus: http.StatusOK,
+			wantReport: health.StatusUp,
+		},
+		{
+			name: "critical down",
+			setup: func(r *health.Registry) {
+				r.Register("database", true, downCheck)
+				r.Register("weather_provider", false, upCheck)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: health.StatusDown,
+		},
+		{
+			name: "non-critical degraded",
+			setup: func(r *health.Registry) {
+				r.Register("database", true, upCheck)
+				r.Register("weather_provider", false, health.WeatherProviderCheck(stubFailureCounter{
+					failures: 5, reason: "status_503",
+				}, 3))
+			},
+			wantStatus: http.StatusOK,
+			wantReport: health.StatusDegraded,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			registry := health.NewRegistry(time.Second)
+			tc.setup(registry)
+
+			r := gin.New()
+			handler.RegisterHealthRoutes(r, handler.NewHealthHandler(registry))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tc.wantStatus, w.Code)
+
+			var report health.Report
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, tc.wantReport, report.Status)
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
+
+func TestSchedulerCheck(t *testing.T) {
+	tracker := scheduler.NewTracker()
+	check := health.SchedulerCheck(tracker, time.Now().Add(-3*time.Hour), 2*time.Hour)
+	assert.Equal(t, health.StatusDegraded, check(context.Background()).Status)
+
+	assert.NoError(t, tracker.Run(func() error { return nil }))
+	assert.Equal(t, health.StatusUp, check(context.Background()).Status)
+
+	assert.Error(t, tracker.Run(func() error { return errors.New("smtp down") }))
+	res := check(context.Background())
+	assert.Equal(t, health.StatusDegraded, res.Status)
+	assert.Equal(t, "smtp down", res.Error)
+}
+
+func TestWeatherProviderCheck(t *testing.T) {
+	lastSuccess := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	res := health.WeatherProviderCheck(stubFailureCounter{failures: 1, lastSuccess: lastSuccess}, 3)(context.Background())
+	assert.Equal(t, health.StatusUp, res.Status)
+	assert.Equal(t, &lastSuccess, res.Details["last_success"])
+	assert.Nil(t, res.Details["last_failure"])
+}
+
+func TestWeatherProviderCheck_DoesNotExposeTheAPIKey(t *testing.T) {
+	provider := weatherapi.NewWeatherAPIProvider("secret-key", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := provider.GetWeather(ctx, "Kyiv")
+	assert.ErrorContains(t, err, "secret-key")
+
+	res := health.WeatherProviderCheck(provider, 1)(context.Background())
+	assert.Equal(t, health.StatusDegraded, res.Status)
+	assert.Equal(t, "transport", res.Error)
+	body, err := json.Marshal(res)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "secret-key")
+	assert.NotNil(t, res.Details["last_failure"])
+}
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", i18n.English},
+		{"uk", i18n.Ukrainian},
+		{"uk-UA,uk;q=0.9,en-US;q=0.8", i18n.Ukrainian},
+		{"en-US,en;q=0.9,uk;q=0.8", i18n.English},
+		{"de-DE,de;q=0.9,uk;q=0.5", i18n.Ukrainian},
+		{"fr, de", i18n.English},
+		{"uk;q=0", i18n.English},
+		{"en;q=0.3, uk;q=0.7", i18n.Ukrainian},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, i18n.FromAcceptLanguage(tc.header), tc.header)
+	}
+}
+
+func TestT_FallsBackToEnglish(t *testing.T) {
+	assert.Equal(t, "Погода: Київ", i18n.T(i18n.Ukrainian, "Weather update for %s", "Київ"))
+	assert.Equal(t, "Weather update for Kyiv", i18n.T(i18n.English, "Weather update for %s", "Kyiv"))
+	assert.Equal(t, "Not translated", i18n.T(i18n.Ukrainian, "Not translated"))
+	assert.Equal(t, i18n.Ukrainian, i18n.Normalize("uk-UA"))
+	assert.Equal(t, i18n.English, i18n.Normalize("pl"))
+}
+
+func TestRender_Ukrainian(t *testing.T) {
+	email, err := templates.Render(i18n.Ukrainian, templates.WeatherUpdate, templates.WeatherUpdateData{
+		City:    "Київ",
+		Weather: model.Weather{Temperature: 5, Humidity: 70, Description: "Хмарно"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Погода: Київ", email.Subject)
+	assert.Contains(t, email.Text, "Температура: 5.0°C")
+	assert.Contains(t, email.HTML, "Відписатися")
+
+	email, err = templates.Render("xx", templates.Confirmation, templates.ConfirmationData{Token: "t"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Confirm your weather subscription", email.Subject)
+}
+
+func TestSubscriptionHandler_Language(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     gin.H
+		header   string
+		wantLang string
+	}{
+		{"from accept-language", gin.H{"email": "a@example.com", "city": "Kyiv", "frequency": "daily"}, "uk-UA,uk;q=0.9", i18n.Ukrainian},
+		{"explicit", gin.H{"email": "a@example.com", "city": "Kyiv", "frequency": "daily", "language": "en"}, "uk", i18n.English},
+		{"default", gin.H{"email": "a@example.com", "city": "Kyiv", "frequency": "daily"}, "", i18n.English},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			svc := &mocks.SubscriptionService{}
+			svc.On("Subscribe", mock.Anything, mock.MatchedBy(func(sub *model.Subscription) bool {
+				return sub.Language == tc.wantLang
+			})).Return(&model.Subscription{}, nil).Once()
+			r := gin.New()
+			r.POST("/subscribe", handler.NewSubscriptionHandler(svc, nil, zap.NewNop()).Subscribe)
+
+			body, _ := json.Marshal(tc.body)
+			req := httptest.NewRequest(http.MethodPost, "/subscribe", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", tc.header)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubscriptionHandler_LocalizesErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/subscribe", handler.NewSubscriptionHandler(&mocks.SubscriptionService{}, nil, zap.NewNop()).Subscribe)
+
+	for header, want := range map[string]string{"uk": "Некоректні дані", "en": "Invalid input"} {
+		req := httptest.NewRequest(http.MethodPost, "/subscribe", bytes.NewBufferString(`{"city":"Kyiv"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var body map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, want, body["error"])
+	}
+}
+
+type localizedProvider struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (p *localizedProvider) GetWeather(ctx context.Context, city string) (*model.Weather, error) {
+	return p.GetLocalizedWeather(ctx, city, i18n.English)
+}
+
+func (p *localizedProvider) GetLocalizedWeather(ctx context.Context, city, lang string) (*model.Weather, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, city+"/"+lang)
+	p.mu.Unlock()
+	description := "Cloudy"
+	if lang == i18n.Ukrainian {
+		description = "Хмарно"
+	}
+	return &model.Weather{Temperature: 10, Humidity: 50, Description: description}, nil
+}
+
+func TestMailJob_LooksUpWeatherPerLanguage(t *testing.T) {
+	subs := []*model.Subscription{
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true},
+		{ID: 2, Email: "b@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true, Language: i18n.Ukrainian},
+		{ID: 3, Email: "c@example.com", City: "kyiv", Frequency: "daily", Confirmed: true, Language: i18n.Ukrainian},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	repo.On("ListDue", mock.Anything, mock.Anything).Return(subs, nil).Once()
+	provider := &localizedProvider{}
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+
+	summary, err := scheduler.MailJob(context.Background(), svc, service.NewWeatherService(provider), scheduler.Options{DryRun: true}, zap.NewNop())
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Kyiv/en", "Kyiv/uk"}, provider.calls)
+	assert.Equal(t, 1, summary.Cities)
+	assert.Contains(t, summary.Previews[0].Body, "Description: Cloudy")
+	assert.Contains(t, summary.Previews[1].Body, "Опис: Хмарно")
+	assert.Equal(t, "Погода: Kyiv", summary.Previews[1].Subject)
+}
@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func setupJobRouter(runs *mocks.JobRunReader) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	admin := handler.RegisterAdminRoutes(r, "secret", handler.NewAdminHandler(nil, zap.NewNop()))
+	handler.RegisterJobRoutes(admin, handler.NewJobHandler(runs, zap.NewNop()))
+	return r
+}
+
+func adminRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	return req
+}
+
+func TestJobHandler_ListJobs(t *testing.T) {
+	started := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	runs := &mocks.JobRunReader{}
+	runs.On("ListRuns", mock.Anything, int64(10), 2).Return([]*model.JobRun{
+		{ID: 9, Name: "mail_job", Status: model.JobRunSucceeded, StartedAt: started, Sent: 3},
+		{ID: 8, Name: "mail_job", Status: model.JobRunFailed, StartedAt: started, Error: "db down"},
+	}, nil)
+	r := setupJobRouter(runs)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/jobs?before=10&limit=2"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Jobs       []handler.JobRunResponse `json:"jobs"`
+		NextBefore int64                    `json:"next_before"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Jobs, 2)
+	assert.Equal(t, 3, body.Jobs[0].Sent)
+	assert.Equal(t, "db down", body.Jobs[1].Error)
+	assert.Equal(t, int64(8), body.NextBefore)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/jobs?limit=0"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/jobs", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJobHandler_GetJob(t *testing.T) {
+	runs := &mocks.JobRunReader{}
+	runs.On("GetRun", mock.Anything, int64(7)).Return(&model.JobRun{ID: 7, Status: model.JobRunSucceeded}, nil)
+	runs.On("ListDeliveries", mock.Anything, int64(7), int64(0), 200).Return([]*model.JobDelivery{
+		{ID: 1, RunID: 7, SubscriptionID: 42, City: "Kyiv", Status: model.DeliveryFailed, Reason: "send_failed", Error: "smtp down"},
+	}, nil)
+	runs.On("GetRun", mock.Anything, int64(8)).Return(nil, gorm.ErrRecordNotFound)
+	r := setupJobRouter(runs)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/jobs/7"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Job        handler.JobRunResponse        `json:"job"`
+		Deliveries []handler.JobDeliveryResponse `json:"deliveries"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(7), body.Job.ID)
+	assert.Equal(t, []handler.JobDeliveryResponse{
+		{ID: 1, SubscriptionID: 42, City: "Kyiv", Status: model.DeliveryFailed, Reason: "send_failed", Error: "smtp down"},
+	}, body.Deliveries)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/jobs/8"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/jobs/abc"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/redact"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "email", in: "sent to john.doe@example.com", want: "sent to j***@example.com"},
+		{name: "uuid", in: "/api/confirm/550e8400-e29b-41d4-a716-446655440000", want: "/api/confirm/550e****"},
+		{
+			name: "api key in url",
+			in:   `Get "https://api.weatherapi.com/v1/current.json?key=abc123&q=Kyiv": timeout`,
+			want: `Get "https://api.weatherapi.com/v1/current.json?key=[REDACTED]&q=Kyiv": timeout`,
+		},
+		{name: "plain", in: "nothing to hide", want: "nothing to hide"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, redact.String(tc.in))
+		})
+	}
+}
+
+func TestRedactCore(t *testing.T) {
+	obsCore, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(redact.NewCore(obsCore))
+
+	logger.With(zap.String("token", "550e8400-e29b-41d4-a716-446655440000")).Info("lookup",
+		zap.String("email", "jane@example.com"),
+		zap.String("password", "hunter2"),
+		zap.Error(errors.New("dial user bob@example.com failed")),
+	)
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "550e****", fields["token"])
+	assert.Equal(t, "j***@example.com", fields["email"])
+	assert.Equal(t, redact.Redacted, fields["password"])
+	assert.Equal(t, "dial user b***@example.com failed", fields["error"])
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	obsCore, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(obsCore)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.AccessLog(logger))
+	r.GET("/ping", func(c *gin.Context) {
+		pkg.FromContext(c.Request.Context(), logger).Info("handler")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(middleware.RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "abc-123", w.Header().Get(middleware.RequestIDHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(middleware.RequestIDHeader, "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	generated := w.Header().Get(middleware.RequestIDHeader)
+	assert.NotEqual(t, "bad id\nwith newline", generated)
+	assert.Len(t, generated, 36)
+
+	entries := logs.All()
+	assert.Len(t, entries, 4)
+	for _, e := range entries[:2] {
+		assert.Equal(t, "abc-123", e.ContextMap()[middleware.RequestIDKey])
+	}
+	assert.Equal(t, "request", entries[1].Message)
+	assert.Equal(t, int64(http.StatusOK), entries[1].ContextMap()["status"])
+	assert.Equal(t, generated, entries[3].ContextMap()[middleware.RequestIDKey])
+}
+
+func TestLogging_SetLevel(t *testing.T) {
+	logging, err := pkg.NewLogging(pkg.LogConfig{
+		Level:           "info",
+		Format:          "json",
+		ComponentLevels: map[string]string{"repo": "warn"},
+	})
+	assert.NoError(t, err)
+
+	repoLogger := logging.Logger("repo")
+	serviceLogger := logging.Logger("service")
+	assert.False(t, repoLogger.Core().Enabled(zapcore.InfoLevel))
+	assert.True(t, serviceLogger.Core().Enabled(zapcore.InfoLevel))
+	assert.False(t, serviceLogger.Core().Enabled(zapcore.DebugLevel))
+
+	assert.NoError(t, logging.SetLevel(pkg.RootComponent, "debug"))
+	assert.True(t, serviceLogger.Core().Enabled(zapcore.DebugLevel))
+	assert.False(t, repoLogger.Core().Enabled(zapcore.InfoLevel))
+
+	assert.NoError(t, logging.SetLevel("repo", "debug"))
+	assert.True(t, repoLogger.Core().Enabled(zapcore.DebugLevel))
+	assert.Equal(t, "debug", logging.Levels()["repo"])
+
+	assert.Error(t, logging.SetLevel("repo", "loud"))
+	_, err = pkg.NewLogging(pkg.LogConfig{Level: "info", Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestLogging_SamplingDropsRepeatedEntries(t *testing.T) {
+	for _, sampling := range []bool{false, true} {
+		var out bytes.Buffer
+		logging, err := pkg.NewLogging(pkg.LogConfig{
+			Level:    "info",
+			Format:   "json",
+			Sampling: sampling,
+			Output:   zapcore.AddSync(&out),
+		})
+		assert.NoError(t, err)
+
+		logger := logging.Logger("service")
+		for i := 0; i < 300; i++ {
+			logger.Info("weather fetched", zap.String("api_key", "abc123"))
+		}
+
+		lines := strings.Count(out.String(), "\n")
+		if sampling {
+			assert.Less(t, lines, 300)
+		} else {
+			assert.Equal(t, 300, lines)
+		}
+		assert.NotContains(t, out.String(), "abc123")
+	}
+}
@@ -0,0 +1,173 @@
+package unit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/mail"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// emailAPIStub records the last request and answers with status.
+type emailAPIStub struct {
+	*httptest.Server
+	status int
+	req    *http.Request
+	body   []byte
+}
+
+func newEmailAPIStub(t *testing.T, status int) *emailAPIStub {
+	stub := &emailAPIStub{status: status}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.req = r
+		stub.body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(stub.status)
+		_, _ = w.Write([]byte(`{"message":"stub"}`))
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+var testUpdate = model.WeatherUpdate{
+	City:             "Kyiv",
+	Weather:          model.Weather{Temperature: 21, Humidity: 40, Description: "Sunny"},
+	UnsubscribeToken: "unsub-tok",
+	Language:         "en",
+}
+
+func TestSendGridTransport(t *testing.T) {
+	stub := newEmailAPIStub(t, http.StatusAccepted)
+	transport, err := mail.NewSendGridTransport(stub.URL, "sg-key")
+	assert.NoError(t, err)
+	mailer := mail.NewMailer(transport, "Weather <from@example.com>", "https://weather.example.com", nil)
+
+	assert.NoError(t, mailer.SendWeatherUpdate(context.Background(), "to@example.com", testUpdate))
+
+	assert.Equal(t, "/v3/mail/send", stub.req.URL.Path)
+	assert.Equal(t, "Bearer sg-key", stub.req.Header.Get("Authorization"))
+	var msg struct {
+		Personalizations []struct {
+			To []struct{ Email string } `json:"to"`
+		} `json:"personalizations"`
+		From    struct{ Email, Name string }   `json:"from"`
+		Subject string                         `json:"subject"`
+		Content []struct{ Type, Value string } `json:"content"`
+		Headers map[string]string              `json:"headers"`
+	}
+	assert.NoError(t, json.Unmarshal(stub.body, &msg))
+	assert.Equal(t, "to@example.com", msg.Personalizations[0].To[0].Email)
+	assert.Equal(t, "from@example.com", msg.From.Email)
+	assert.Equal(t, "Weather", msg.From.Name)
+	assert.Contains(t, msg.Subject, "Kyiv")
+	assert.Len(t, msg.Content, 2)
+	assert.Equal(t, "<https://weather.example.com/api/unsubscribe/unsub-tok>", msg.Headers["List-Unsubscribe"])
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Headers["List-Unsubscribe-Post"])
+}
+
+func TestMailgunTransport(t *testing.T) {
+	stub := newEmailAPIStub(t, http.StatusOK)
+	transport, err := mail.NewMailgunTransport(stub.URL, "mg.example.com", "mg-key")
+	assert.NoError(t, err)
+	mailer := mail.NewMailer(transport, "from@example.com", "https://weather.example.com", nil)
+
+	assert.NoError(t, mailer.SendConfirmation(context.Background(), "to@example.com", "tok", "en"))
+
+	assert.Equal(t, "/v3/mg.example.com/messages.mime", stub.req.URL.Path)
+	user, pass, ok := stub.req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "api", user)
+	assert.Equal(t, "mg-key", pass)
+	body := string(stub.body)
+	assert.Contains(t, body, `name="to"`)
+	assert.Contains(t, body, "To: to@example.com")
+	assert.Contains(t, body, "/api/confirm/tok")
+}
+
+func TestSESTransport(t *testing.T) {
+	stub := newEmailAPIStub(t, http.StatusOK)
+	transport, err := mail.NewSESTransport(stub.URL, mail.SESCredentials{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	assert.NoError(t, err)
+	mailer := mail.NewMailer(transport, "from@example.com", "https://weather.example.com", nil)
+
+	assert.NoError(t, mailer.SendWeatherUpdate(context.Background(), "to@example.com", testUpdate))
+
+	assert.Equal(t, "/v2/email/outbound-emails", stub.req.URL.Path)
+	auth := stub.req.Header.Get("Authorization")
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+	assert.Contains(t, auth, "/eu-west-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=")
+	var msg struct {
+		FromEmailAddress string
+		Destination      struct{ ToAddresses []string }
+		Content          struct{ Raw struct{ Data string } }
+	}
+	assert.NoError(t, json.Unmarshal(stub.body, &msg))
+	assert.Equal(t, []string{"to@example.com"}, msg.Destination.ToAddresses)
+	raw, err := base64.StdEncoding.DecodeString(msg.Content.Raw.Data)
+	assert.NoError(t, err)
+	assert.Contains(t, string(raw), "List-Unsubscribe: <https://weather.example.com/api/unsubscribe/unsub-tok>")
+}
+
+// TestSignV4 checks the signer against the example request in the AWS
+// Signature Version 4 documentation.
+func TestSignV4(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds := mail.SESCredentials{Region: "us-east-1", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	mail.SignV4(req, nil, creds, "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
+
+func TestAPIError_Classification(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+		temporary bool
+	}{
+		{http.StatusBadRequest, true, false},
+		{http.StatusUnprocessableEntity, true, false},
+		{http.StatusUnauthorized, false, false},
+		{http.StatusForbidden, false, false},
+		{http.StatusTooManyRequests, false, true},
+		{http.StatusServiceUnavailable, false, true},
+	}
+	for _, tc := range tests {
+		stub := newEmailAPIStub(t, tc.status)
+		transport, err := mail.NewSendGridTransport(stub.URL, "sg-key")
+		assert.NoError(t, err)
+
+		err = mail.NewMailer(transport, "from@example.com", "https://weather.example.com", nil).
+			SendConfirmation(context.Background(), "to@example.com", "tok", "en")
+
+		var apiErr *mail.APIError
+		assert.True(t, errors.As(err, &apiErr), tc.status)
+		assert.Equal(t, tc.status, apiErr.StatusCode)
+		assert.Equal(t, tc.permanent, apiErr.Permanent(), tc.status)
+		assert.Equal(t, tc.temporary, apiErr.Temporary(), tc.status)
+	}
+}
+
+func TestAPITransports_RequireCredentials(t *testing.T) {
+	_, err := mail.NewSendGridTransport("", "")
+	assert.Error(t, err)
+	_, err = mail.NewMailgunTransport("", "", "key")
+	assert.Error(t, err)
+	_, err = mail.NewSESTransport("", mail.SESCredentials{Region: "eu-west-1"})
+	assert.Error(t, err)
+	_, err = mail.NewSendGridTransport("not a url", "key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/metrics"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type countingSubscriptionCounter struct {
+	calls  int
+	counts map[string]int64
+	err    error
+}
+
+func (c *countingSubscriptionCounter) CountSubscriptionsByState() (map[string]int64, error) {
+	c.calls++
+	return c.counts, c.err
+}
+
+func TestSubscriptionsCollector_CachesCounts(t *testing.T) {
+	counter := &countingSubscriptionCounter{counts: map[string]int64{"confirmed": 3, "pending": 1}}
+	clk := clock.NewFake(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC))
+	collector := metrics.NewSubscriptionsCollector(counter, time.Minute, nil)
+	collector.Clock = clk
+
+	assert.Equal(t, 2, testutil.CollectAndCount(collector))
+	assert.Equal(t, 2, testutil.CollectAndCount(collector))
+	assert.Equal(t, 1, counter.calls)
+
+	clk.Set(clk.Now().Add(time.Minute))
+	counter.err = errors.New("connection refused")
+	assert.Equal(t, 2, testutil.CollectAndCount(collector), "a failed refresh keeps the last counts")
+	assert.Equal(t, 2, counter.calls)
+}
+
+func TestGinMiddleware_CountsPanickingRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(metrics.GinMiddleware())
+	r.Use(middleware.Recovery(zap.NewNop()))
+	r.GET("/panics", func(c *gin.Context) { panic("boom") })
+
+	served := metrics.HTTPRequests.WithLabelValues("/panics", http.MethodGet, "500")
+	before := testutil.ToFloat64(served)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panics", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(served))
+}
@@ -0,0 +1,250 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/channel"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// stubChat records the JSON bodies posted to it by path.
+type stubChat struct {
+	mu     sync.Mutex
+	bodies map[string]map[string]any
+	status int
+}
+
+func newStubChat(t *testing.T) (*stubChat, *httptest.Server) {
+	stub := &stubChat{bodies: map[string]map[string]any{}, status: http.StatusOK}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		stub.mu.Lock()
+		stub.bodies[r.URL.Path] = body
+		status := stub.status
+		stub.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+var kyivUpdate = model.WeatherUpdate{
+	City:             "Kyiv",
+	Weather:          model.Weather{Temperature: 21.5, Humidity: 40, Description: "Sunny"},
+	UnsubscribeToken: "unsub",
+	Language:         "en",
+}
+
+func TestSubscription_Target(t *testing.T) {
+	channel, destination := (&model.Subscription{Email: "a@example.com"}).Target()
+	assert.Equal(t, model.ChannelEmail, channel)
+	assert.Equal(t, "a@example.com", destination)
+
+	channel, destination = (&model.Subscription{Email: "a@example.com", Channel: model.ChannelTelegram, Destination: "42"}).Target()
+	assert.Equal(t, model.ChannelTelegram, channel)
+	assert.Equal(t, "42", destination)
+
+	_, destination = (&model.Subscription{Email: "a@example.com", Channel: model.ChannelEmail, Destination: "victim@example.com"}).Target()
+	assert.Equal(t, "a@example.com", destination)
+}
+
+func TestSubscribe_EmailDestinationMustBeSubscribedAddress(t *testing.T) {
+	repo := &mocks.SubscriptionRepository{}
+	svc := service.NewSubscriptionService(repo, nil, zap.NewNop())
+
+	_, err := svc.Subscribe(context.Background(), &model.Subscription{
+		Email: "me@example.com", City: "Kyiv", Frequency: "daily",
+		Channel: model.ChannelEmail, Destination: "victim@example.com",
+	})
+
+	assert.ErrorIs(t, err, notify.ErrInvalidDestination)
+	repo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	r := notify.NewRegistry("https://weather.example.com")
+	r.Register(model.ChannelEmail, notify.MailNotifier{Mailer: &mocks.Mailer{}})
+	r.Register(model.ChannelSlack, channel.NewSlackNotifier("https://hooks.slack.com/services", nil))
+	r.Register(model.ChannelWebhook, channel.NewWebhookNotifier("", nil))
+
+	assert.NoError(t, r.Validate(model.ChannelEmail, ""))
+	assert.NoError(t, r.Validate(model.ChannelSlack, "https://hooks.slack.com/services/T0/B0/x"))
+	assert.NoError(t, r.Validate(model.ChannelWebhook, "https://example.com/hook"))
+
+	assert.ErrorIs(t, r.Validate(model.ChannelTelegram, "42"), notify.ErrUnknownChannel)
+	assert.ErrorIs(t, r.Validate(model.ChannelEmail, "not an address"), notify.ErrInvalidDestination)
+	assert.ErrorIs(t, r.Validate(model.ChannelSlack, "https://evil.example.com/services/x"), notify.ErrInvalidDestination)
+	assert.ErrorIs(t, r.Validate(model.ChannelSlack, "https://hooks.slack.com/other/x"), notify.ErrInvalidDestination)
+	assert.ErrorIs(t, r.Validate(model.ChannelWebhook, "http://example.com/hook"), notify.ErrInvalidDestination)
+}
+
+func TestTelegramNotifier_SendsRenderedText(t *testing.T) {
+	stub, srv := newStubChat(t)
+	r := notify.NewRegistry("https://weather.example.com")
+	r.Register(model.ChannelTelegram, channel.NewTelegramNotifier(srv.URL, "bot-token", nil))
+
+	err := r.Notify(context.Background(), notify.Target{Channel: model.ChannelTelegram, Destination: "-100123"}, kyivUpdate)
+
+	assert.NoError(t, err)
+	body := stub.bodies["/botbot-token/sendMessage"]
+	assert.Equal(t, "-100123", body["chat_id"])
+	assert.Contains(t, body["text"], "Weather in Kyiv")
+	assert.Contains(t, body["text"], "https://weather.example.com/api/unsubscribe/unsub")
+}
+
+func TestTelegramNotifier_RedactsTokenFromErrors(t *testing.T) {
+	n := channel.NewTelegramNotifier("http://127.0.0.1:1", "secret-token", nil)
+
+	err := n.Notify(context.Background(), "42", notify.Message{Update: kyivUpdate, Text: "hi"})
+
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+func TestIncomingWebhookNotifier_RedactsURLFromErrors(t *testing.T) {
+	_, srv := newStubChat(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	slack := channel.NewSlackNotifier(srv.URL+"/services", zap.New(core))
+	destination := srv.URL + "/services/T0/B0/secret-part"
+	srv.Close()
+
+	err := slack.Notify(context.Background(), destination, notify.Message{Update: kyivUpdate, Text: "hi"})
+
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-part")
+	for _, entry := range logs.All() {
+		for _, field := range entry.Context {
+			assert.NotContains(t, fmt.Sprint(field.Interface, field.String), "secret-part")
+		}
+	}
+}
+
+func TestIncomingWebhookNotifiers(t *testing.T) {
+	stub, srv := newStubChat(t)
+	slack := channel.NewSlackNotifier(srv.URL+"/services", nil)
+	discord := channel.NewDiscordNotifier(srv.URL+"/api/webhooks", nil)
+	msg := notify.Message{Update: kyivUpdate, Text: "Weather in Kyiv"}
+
+	assert.NoError(t, slack.Notify(context.Background(), srv.URL+"/services/T0/B0/x", msg))
+	assert.NoError(t, discord.Notify(context.Background(), srv.URL+"/api/webhooks/1/abc", msg))
+	assert.Equal(t, "Weather in Kyiv", stub.bodies["/services/T0/B0/x"]["text"])
+	assert.Equal(t, "Weather in Kyiv", stub.bodies["/api/webhooks/1/abc"]["content"])
+
+	stub.status = http.StatusNotFound
+	assert.Error(t, slack.Notify(context.Background(), srv.URL+"/services/T0/B0/gone", msg))
+	assert.Error(t, slack.Notify(context.Background(), "https://elsewhere.example.com/services/x", msg))
+}
+
+func TestWebhookNotifier_PostsWeatherPayload(t *testing.T) {
+	stub, srv := newStubChat(t)
+	n := channel.NewWebhookNotifier(srv.URL, nil)
+
+	err := n.Notify(context.Background(), srv.URL+"/hooks/1", notify.Message{
+		Update:         kyivUpdate,
+		Subject:        "Weather update for Kyiv",
+		Text:           "Weather in Kyiv",
+		UnsubscribeURL: "https://weather.example.com/api/unsubscribe/unsub",
+	})
+
+	assert.NoError(t, err)
+	body := stub.bodies["/hooks/1"]
+	assert.Equal(t, "Kyiv", body["city"])
+	assert.Equal(t, "Weather update for Kyiv", body["subject"])
+	assert.Equal(t, "https://weather.example.com/api/unsubscribe/unsub", body["unsubscribe_url"])
+	assert.Equal(t, map[string]any{"temperature": 21.5, "humidity": float64(40), "description": "Sunny"}, body["weather"])
+}
+
+// recordingNotifier remembers where it was asked to deliver.
+type recordingNotifier struct {
+	destinations []string
+	err          error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, destination string, msg notify.Message) error {
+	n.destinations = append(n.destinations, destination)
+	return n.err
+}
+
+func TestDispatcher_RoutesWeatherUpdatesByChannel(t *testing.T) {
+	weather := &model.Weather{Description: "rain"}
+	store := &memoryOutbox{
+		messages: []*model.OutboxMessage{
+			{ID: 1, Kind: model.OutboxWeatherUpdate, Recipient: "a@example.com", Payload: model.OutboxPayload{Token: "unsub-a", City: "Kyiv", Weather: weather, Channel: model.ChannelTelegram, Destination: "42"}, Status: model.OutboxPending},
+			{ID: 2, Kind: model.OutboxWeatherUpdate, Recipient: "b@example.com", Payload: model.OutboxPayload{Token: "unsub-b", City: "Kyiv", Weather: weather}, Status: model.OutboxPending},
+			{ID: 3, Kind: model.OutboxWeatherUpdate, Recipient: "c@example.com", Payload: model.OutboxPayload{Token: "unsub-c", City: "Kyiv", Weather: weather, Channel: model.ChannelDiscord, Destination: "x"}, Status: model.OutboxPending},
+		},
+		retries: map[int64]time.Time{},
+	}
+	mailer := &mocks.Mailer{}
+	mailer.On("SendWeatherUpdate", mock.Anything, "b@example.com", model.WeatherUpdate{City: "Kyiv", Weather: *weather, UnsubscribeToken: "unsub-b"}).Return(nil).Once()
+	telegram := &recordingNotifier{}
+	registry := notify.NewRegistry("")
+	registry.Register(model.ChannelEmail, notify.MailNotifier{Mailer: mailer})
+	registry.Register(model.ChannelTelegram, telegram)
+
+	_, err := outbox.NewDispatcher(store, mailer, nil, outbox.Config{Notifiers: registry}, zap.NewNop()).DispatchOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"42"}, telegram.destinations)
+	assert.Equal(t, model.OutboxSent, store.find(1).Status)
+	assert.Equal(t, model.OutboxSent, store.find(2).Status)
+	assert.Equal(t, model.OutboxPending, store.find(3).Status)
+	assert.Contains(t, store.find(3).LastError, "unknown notification channel")
+	mailer.AssertExpectations(t)
+}
+
+func TestSubscribe_RejectsInvalidDestination(t *testing.T) {
+	repo := &mocks.SubscriptionRepository{}
+	registry := notify.NewRegistry("")
+	registry.Register(model.ChannelEmail, notify.MailNotifier{})
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+	svc.Channels = registry
+
+	_, err := svc.Subscribe(context.Background(), &model.Subscription{Email: "a@example.com", City: "Kyiv", Frequency: "daily", Channel: model.ChannelSlack, Destination: "https://hooks.slack.com/services/x"})
+
+	assert.True(t, errors.Is(err, notify.ErrUnknownChannel))
+	repo.AssertNotCalled(t, "CreateWithOutbox", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMailJob_FailsSubscriptionsOnUnavailableChannel(t *testing.T) {
+	subs := []*model.Subscription{
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true},
+		{ID: 2, Email: "b@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true, Channel: model.ChannelTelegram, Destination: "42"},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	repo.On("ClaimDue", mock.Anything, mock.Anything).Return(subs, nil)
+	repo.On("UpdateWithOutbox", mock.Anything, mock.Anything, mock.MatchedBy(func(msg *model.OutboxMessage) bool {
+		return msg.Payload.Channel == model.ChannelEmail && msg.Payload.Destination == "a@example.com"
+	})).Return(nil).Once()
+	registry := notify.NewRegistry("")
+	registry.Register(model.ChannelEmail, notify.MailNotifier{})
+
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+	ws := service.NewWeatherService(&countingWeatherProvider{})
+
+	summary, err := scheduler.MailJob(context.Background(), svc, ws, scheduler.Options{Channels: registry}, zap.NewNop())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Sent)
+	assert.Equal(t, 1, summary.FailedByReason[scheduler.ReasonChannelUnavailable])
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,254 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/mail"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 5*time.Minute
+	assert.Equal(t, 30*time.Second, outbox.Backoff(1, base, max))
+	assert.Equal(t, time.Minute, outbox.Backoff(2, base, max))
+	assert.Equal(t, 4*time.Minute, outbox.Backoff(4, base, max))
+	assert.Equal(t, max, outbox.Backoff(5, base, max))
+	assert.Equal(t, max, outbox.Backoff(100, base, max))
+}
+
+// memoryOutbox hands out every pending message on each claim, ignoring
+// next_attempt_at, so tests can drive retries without waiting.
+type memoryOutbox struct {
+	mu       sync.Mutex
+	messages []*model.OutboxMessage
+	retries  map[int64]time.Time
+}
+
+func (o *memoryOutbox) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var due []*model.OutboxMessage
+	for _, m := range o.messages {
+		if m.Status == model.OutboxPending && len(due) < limit {
+			claimed := *m
+			due = append(due, &claimed)
+		}
+	}
+	return due, nil
+}
+
+func (o *memoryOutbox) find(id int64) *model.OutboxMessage {
+	for _, m := range o.messages {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (o *memoryOutbox) MarkSent(ctx context.Context, id int64, attempts int, at time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.find(id)
+	m.Status, m.Attempts, m.SentAt = model.OutboxSent, attempts, &at
+	return nil
+}
+
+func (o *memoryOutbox) MarkRetry(ctx context.Context, id int64, attempts int, next time.Time, lastErr string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.find(id)
+	m.Attempts, m.NextAttemptAt, m.LastError = attempts, next, lastErr
+	o.retries[id] = next
+	return nil
+}
+
+func (o *memoryOutbox) MarkDead(ctx context.Context, id int64, attempts int, lastErr string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.find(id)
+	m.Status, m.Attempts, m.LastError = model.OutboxDead, attempts, lastErr
+	return nil
+}
+
+func TestDispatcher_RetriesAndDeadLetters(t *testing.T) {
+	deliveryID := int64(5)
+	store := &memoryOutbox{
+		messages: []*model.OutboxMessage{
+			{ID: 1, Kind: model.OutboxConfirmation, Recipient: "a@example.com", Payload: model.OutboxPayload{Token: "tok", Language: "uk"}, Status: model.OutboxPending},
+			{ID: 2, Kind: model.OutboxWeatherUpdate, Recipient: "b@example.com", Payload: model.OutboxPayload{Token: "unsub-b", City: "Kyiv", Weather: &model.Weather{Description: "sunny"}}, DeliveryID: &deliveryID, Status: model.OutboxPending},
+		},
+		retries: map[int64]time.Time{},
+	}
+	mailer := &mocks.Mailer{}
+	mailer.On("SendConfirmation", mock.Anything, "a@example.com", "tok", "uk").Return(errors.New("421 try later")).Once()
+	mailer.On("SendConfirmation", mock.Anything, "a@example.com", "tok", "uk").Return(nil).Once()
+	mailer.On("SendWeatherUpdate", mock.Anything, "b@example.com", model.WeatherUpdate{City: "Kyiv", Weather: model.Weather{Description: "sunny"}, UnsubscribeToken: "unsub-b"}).Return(errors.New("smtp down"))
+	ledger := &memoryLedger{entries: map[string]*model.Delivery{
+		"5": {ID: deliveryID, Status: model.DeliveryPending},
+	}}
+
+	d := outbox.NewDispatcher(store, mailer, ledger, outbox.Config{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour}, zap.NewNop())
+
+	before := time.Now()
+	n, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.WithinDuration(t, before.Add(time.Minute), store.retries[1], 5*time.Second)
+	assert.Equal(t, 1, store.find(1).Attempts)
+	assert.Equal(t, "421 try later", store.find(1).LastError)
+
+	for i := 0; i < 2; i++ {
+		_, err = d.DispatchOnce(context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, model.OutboxSent, store.find(1).Status)
+	assert.Equal(t, 2, store.find(1).Attempts)
+	assert.Equal(t, model.OutboxDead, store.find(2).Status)
+	assert.Equal(t, 3, store.find(2).Attempts)
+	assert.Equal(t, model.DeliveryFailed, ledger.entries["5"].Status)
+	mailer.AssertExpectations(t)
+
+	n, err = d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestDispatcher_MarksLedgerSent(t *testing.T) {
+	deliveryID := int64(9)
+	store := &memoryOutbox{
+		messages: []*model.OutboxMessage{
+			{ID: 1, Kind: model.OutboxWeatherUpdate, Recipient: "a@example.com", Payload: model.OutboxPayload{Token: "unsub-a", City: "Kyiv", Weather: &model.Weather{Description: "rain"}}, DeliveryID: &deliveryID, Status: model.OutboxPending},
+		},
+		retries: map[int64]time.Time{},
+	}
+	mailer := &mocks.Mailer{}
+	mailer.On("SendWeatherUpdate", mock.Anything, "a@example.com", model.WeatherUpdate{City: "Kyiv", Weather: model.Weather{Description: "rain"}, UnsubscribeToken: "unsub-a"}).Return(nil).Once()
+	ledger := &memoryLedger{entries: map[string]*model.Delivery{
+		"9": {ID: deliveryID, Status: model.DeliveryPending},
+	}}
+
+	_, err := outbox.NewDispatcher(store, mailer, ledger, outbox.Config{}, zap.NewNop()).DispatchOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.OutboxSent, store.find(1).Status)
+	assert.Equal(t, model.DeliverySent, ledger.entries["9"].Status)
+	mailer.AssertExpectations(t)
+}
+
+type memoryRecipients struct {
+	undeliverable map[string]string
+}
+
+func (r *memoryRecipients) MarkUndeliverable(ctx context.Context, email, reason string, at time.Time) error {
+	r.undeliverable[email] = reason
+	return nil
+}
+
+func TestDispatcher_DeadLettersPermanentFailuresAndMarksRecipient(t *testing.T) {
+	store := &memoryOutbox{
+		messages: []*model.OutboxMessage{
+			{ID: 1, Kind: model.OutboxConfirmation, Recipient: "gone@example.com", Payload: model.OutboxPayload{Token: "tok"}, Status: model.OutboxPending},
+			{ID: 2, Kind: model.OutboxConfirmation, Recipient: "busy@example.com", Payload: model.OutboxPayload{Token: "tok"}, Status: model.OutboxPending},
+			{ID: 3, Kind: model.OutboxConfirmation, Recipient: "spam@example.com", Payload: model.OutboxPayload{Token: "tok"}, Status: model.OutboxPending},
+		},
+		retries: map[int64]time.Time{},
+	}
+	mailer := &mocks.Mailer{}
+	mailer.On("SendConfirmation", mock.Anything, "gone@example.com", "tok", "").
+		Return(fmt.Errorf("send: %w", &mail.SMTPError{Command: mail.CommandRcpt, Code: 550, EnhancedCode: "5.1.1", Message: "5.1.1 mailbox unavailable"})).Once()
+	mailer.On("SendConfirmation", mock.Anything, "busy@example.com", "tok", "").
+		Return(&mail.SMTPError{Command: mail.CommandRcpt, Code: 452, Message: "4.2.2 mailbox full"}).Once()
+	mailer.On("SendConfirmation", mock.Anything, "spam@example.com", "tok", "").
+		Return(&mail.SMTPError{Command: mail.CommandData, Code: 554, EnhancedCode: "5.7.1", Message: "5.7.1 message rejected as spam"}).Once()
+	recipients := &memoryRecipients{undeliverable: map[string]string{}}
+
+	d := outbox.NewDispatcher(store, mailer, nil, outbox.Config{MaxAttempts: 5, Recipients: recipients}, zap.NewNop())
+	_, err := d.DispatchOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.OutboxDead, store.find(1).Status)
+	assert.Equal(t, 1, store.find(1).Attempts)
+	assert.Equal(t, model.OutboxPending, store.find(2).Status)
+	assert.Contains(t, store.retries, int64(2))
+	assert.Equal(t, model.OutboxDead, store.find(3).Status)
+	assert.Equal(t, map[string]string{"gone@example.com": "send: smtp RCPT: 550 5.1.1 mailbox unavailable"}, recipients.undeliverable)
+	mailer.AssertExpectations(t)
+}
+
+func setupOutboxRouter(admin *mocks.OutboxAdmin) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	group := handler.RegisterAdminRoutes(r, "secret", handler.NewAdminHandler(nil, zap.NewNop()))
+	handler.RegisterOutboxRoutes(group, handler.NewOutboxHandler(admin, zap.NewNop()))
+	return r
+}
+
+func TestOutboxHandler_ListMessages(t *testing.T) {
+	admin := &mocks.OutboxAdmin{}
+	admin.On("ListMessages", mock.Anything, model.OutboxDead, int64(0), 50).Return([]*model.OutboxMessage{
+		{ID: 3, Kind: model.OutboxConfirmation, Recipient: "a@example.com", Payload: model.OutboxPayload{Token: "secret-token"}, Status: model.OutboxDead, Attempts: 8, LastError: "550 mailbox unavailable"},
+	}, nil)
+	r := setupOutboxRouter(admin)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/outbox?status=dead"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, strings.Contains(w.Body.String(), "secret-token"))
+	var body struct {
+		Messages []handler.OutboxMessageResponse `json:"messages"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Messages, 1)
+	assert.Equal(t, 8, body.Messages[0].Attempts)
+	assert.Equal(t, "550 mailbox unavailable", body.Messages[0].LastError)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/outbox?status=bogus"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOutboxHandler_Replay(t *testing.T) {
+	admin := &mocks.OutboxAdmin{}
+	admin.On("Replay", mock.Anything, int64(3)).Return(&model.OutboxMessage{ID: 3, Status: model.OutboxPending}, nil)
+	admin.On("Replay", mock.Anything, int64(4)).Return(nil, outbox.ErrNotReplayable)
+	admin.On("Replay", mock.Anything, int64(5)).Return(nil, gorm.ErrRecordNotFound)
+	admin.On("GetMessage", mock.Anything, int64(5)).Return(nil, gorm.ErrRecordNotFound)
+	r := setupOutboxRouter(admin)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("POST", "/admin/outbox/3/replay"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("POST", "/admin/outbox/4/replay"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("POST", "/admin/outbox/5/replay"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/admin/outbox/5"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
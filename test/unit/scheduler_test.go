@@ -0,0 +1,504 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestMailJob_StopsAtCheckpointWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subs := []*model.Subscription{
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true},
+		{ID: 2, Email: "b@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	mailer := &mocks.Mailer{}
+	repo.On("ClaimDue", mock.Anything, mock.Anything).Return(subs, nil)
+	repo.On("UpdateWithOutbox", mock.Anything, subs[0], mock.Anything).
+		Run(func(args mock.Arguments) { cancel() }).
+		Return(func(ctx context.Context, sub *model.Subscription, msg *model.OutboxMessage) error {
+			assert.NoError(t, ctx.Err())
+			return nil
+		}).Once()
+
+	svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
+	ws := service.NewWeatherService(&countingWeatherProvider{})
+
+	summary, err := scheduler.MailJob(ctx, svc, ws, scheduler.Options{Concurrency: 1}, zap.NewNop())
+
+	assert.ErrorIs(t, err, scheduler.ErrInterrupted)
+	assert.Equal(t, 1, summary.Sent)
+	assert.Equal(t, 1, summary.SkippedByReason[scheduler.ReasonInterrupted])
+	assert.NotNil(t, subs[0].LastSentAt)
+	assert.Nil(t, subs[1].LastSentAt)
+	repo.AssertExpectations(t)
+	mailer.AssertExpectations(t)
+}
+
+type cityCountingProvider struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *cityCountingProvider) GetWeather(ctx context.Context, city string) (*model.Weather, error) {
+	p.mu.Lock()
+	p.calls[city]++
+	p.mu.Unlock()
+	if city == "Atlantis" {
+		return nil, errors.New("city not found")
+	}
+	return &model.Weather{Temperature: 10, Humidity: 50, Description: "Cloudy"}, nil
+}
+
+func TestMailJob_GroupsByCityAndSummarizes(t *testing.T) {
+	subs := []*model.Subscription{
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true},
+		{ID: 2, Email: "b@example.com", City: "kyiv ", Frequency: "daily", Confirmed: true},
+		{ID: 3, Email: "c@example.com", City: "KYIV", Frequency: "hourly", Confirmed: true},
+		{ID: 4, Email: "d@example.com", City: "Atlantis", Frequency: "hourly", Confirmed: true},
+		{ID: 5, Email: "e@example.com", City: "Lviv", Frequency: "daily", Confirmed: true},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	mailer := &mocks.Mailer{}
+	repo.On("ClaimDue", mock.Anything, mock.MatchedBy(func(q service.ClaimQuery) bool { return q.AfterID == 0 })).Return(subs[:2], nil).Once()
+	repo.On("ClaimDue", mock.Anything, mock.MatchedBy(func(q service.ClaimQuery) bool { return q.AfterID == 2 })).Return(subs[2:4], nil).Once()
+	repo.On("ClaimDue", mock.Anything, mock.MatchedBy(func(q service.ClaimQuery) bool { return q.AfterID == 4 })).Return(subs[4:], nil).Once()
+	repo.On("UpdateWithOutbox", mock.Anything, mock.MatchedBy(func(sub *model.Subscription) bool { return sub.Email == "c@example.com" }), mock.Anything).
+		Return(errors.New("db down"))
+	repo.On("UpdateWithOutbox", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	provider := &cityCountingProvider{calls: map[string]int{}}
+	svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
+	ws := service.NewWeatherService(provider)
+
+	summary, err := scheduler.MailJob(context.Background(), svc, ws, scheduler.Options{Concurrency: 3, BatchSize: 2}, zap.NewNop())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Kyiv": 1, "Atlantis": 1, "Lviv": 1}, provider.calls)
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, 3, summary.Cities)
+	assert.Equal(t, 3, summary.Sent)
+	assert.Equal(t, 0, summary.Skipped)
+	assert.Equal(t, 2, summary.Failed)
+	assert.Equal(t, map[string]int{scheduler.ReasonWeatherUnavailable: 1, scheduler.ReasonQueueFailed: 1}, summary.FailedByReason)
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "UpdateWithOutbox", 4)
+}
+
+type fakeLocker struct {
+	held     map[string]bool
+	unlocked int
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	if l.held[name] {
+		return nil, false, nil
+	}
+	l.held[name] = true
+	return func() {
+		l.held[name] = false
+		l.unlocked++
+	}, true, nil
+}
+
+func TestRunLocked(t *testing.T) {
+	locker := &fakeLocker{held: map[string]bool{}}
+	runs := 0
+	job := func() error {
+		runs++
+		return nil
+	}
+
+	err := scheduler.RunLocked(context.Background(), locker, "mail_job", func() error {
+		assert.ErrorIs(t, scheduler.RunLocked(context.Background(), locker, "mail_job", job), scheduler.ErrLocked)
+		return job()
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, 1, locker.unlocked)
+	assert.NoError(t, scheduler.RunLocked(context.Background(), nil, "mail_job", job))
+	assert.Equal(t, 2, runs)
+}
+
+type memoryHistory struct {
+	mu         sync.Mutex
+	runs       []*model.JobRun
+	deliveries []*model.JobDelivery
+}
+
+func (h *memoryHistory) StartRun(ctx context.Context, run *model.JobRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	run.ID = int64(len(h.runs) + 1)
+	h.runs = append(h.runs, run)
+	return nil
+}
+
+func (h *memoryHistory) FinishRun(ctx context.Context, run *model.JobRun) error {
+	return nil
+}
+
+func (h *memoryHistory) AddDeliveries(ctx context.Context, deliveries []*model.JobDelivery) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.deliveries = append(h.deliveries, deliveries...)
+	return nil
+}
+
+func TestMailJob_RecordsHistory(t *testing.T) {
+	subs := []*model.Subscription{
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true},
+		{ID: 2, Email: "b@example.com", City: "Atlantis", Frequency: "hourly", Confirmed: true},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	mailer := &mocks.Mailer{}
+	repo.On("ClaimDue", mock.Anything, mock.Anything).Return(subs, nil)
+	repo.On("UpdateWithOutbox", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	history := &memoryHistory{}
+	svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
+	ws := service.NewWeatherService(&countingWeatherProvider{})
+
+	summary, err := scheduler.MailJob(context.Background(), svc, ws, scheduler.Options{History: history, WorkerID: "w1"}, zap.NewNop())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), summary.RunID)
+	assert.Len(t, history.runs, 1)
+	run := history.runs[0]
+	assert.Equal(t, scheduler.MailJobName, run.Name)
+	assert.Equal(t, "w1", run.WorkerID)
+	assert.Equal(t, model.JobRunSucceeded, run.Status)
+	assert.NotNil(t, run.FinishedAt)
+	assert.Equal(t, 2, run.Total)
+	assert.Equal(t, 1, run.Sent)
+	assert.Equal(t, 1, run.Failed)
+
+	byID := map[int64]*model.JobDelivery{}
+	for _, d := range history.deliveries {
+		assert.Equal(t, int64(1), d.RunID)
+		byID[d.SubscriptionID] = d
+	}
+	assert.Equal(t, model.DeliverySent, byID[1].Status)
+	assert.Equal(t, model.DeliveryFailed, byID[2].Status)
+	assert.Equal(t, scheduler.ReasonWeatherUnavailable, byID[2].Reason)
+	assert.Equal(t, "city not found", byID[2].Error)
+}
+
+type memoryLedger struct {
+	mu      sync.Mutex
+	entries map[string]*model.Delivery
+}
+
+func (l *memoryLedger) key(id int64, period time.Time) string {
+	return fmt.Sprintf("%d/%s", id, period.Format(time.RFC3339))
+}
+
+func (l *memoryLedger) Reserve(ctx context.Context, subscriptionID int64, period time.Time) (*model.Delivery, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	k := l.key(subscriptionID, period)
+	if e, ok := l.entries[k]; ok {
+		if e.Status != model.DeliveryFailed {
+			return nil, false, nil
+		}
+		e.Status = model.DeliveryPending
+		e.Attempts++
+		return e, true, nil
+	}
+	e := &model.Delivery{ID: int64(len(l.entries) + 1), SubscriptionID: subscriptionID, Period: period, Status: model.DeliveryPending, Attempts: 1}
+	l.entries[k] = e
+	return e, true, nil
+}
+
+func (l *memoryLedger) set(id int64, status string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.entries {
+		if e.ID == id {
+			e.Status = status
+		}
+	}
+}
+
+func (l *memoryLedger) MarkSent(ctx context.Context, id int64, at time.Time) error {
+	l.set(id, model.DeliverySent)
+	return nil
+}
+
+func (l *memoryLedger) MarkFailed(ctx context.Context, id int64, reason string) error {
+	l.set(id, model.DeliveryFailed)
+	return nil
+}
+
+func TestMailJob_LedgerSendsAtMostOncePerPeriod(t *testing.T) {
+	subs := []*model.Subscription{
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true},
+		{ID: 2, Email: "b@example.com", City: "Kyiv", Frequency: "daily", Confirmed: true},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	mailer := &mocks.Mailer{}
+	repo.On("ClaimDue", mock.Anything, mock.Anything).Return(subs, nil)
+	// ClaimDue keeps returning both subscribers, as if LastSentAt was never saved.
+	var queued []*model.OutboxMessage
+	record := func(args mock.Arguments) { queued = append(queued, args.Get(2).(*model.OutboxMessage)) }
+	isA := mock.MatchedBy(func(sub *model.Subscription) bool { return sub.Email == "a@example.com" })
+	isB := mock.MatchedBy(func(sub *model.Subscription) bool { return sub.Email == "b@example.com" })
+	repo.On("UpdateWithOutbox", mock.Anything, isA, mock.Anything).Run(record).Return(nil).Once()
+	repo.On("UpdateWithOutbox", mock.Anything, isB, mock.Anything).Return(errors.New("db down")).Once()
+	repo.On("UpdateWithOutbox", mock.Anything, isB, mock.Anything).Run(record).Return(nil).Once()
+
+	ledger := &memoryLedger{entries: map[string]*model.Delivery{}}
+	svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
+	ws := service.NewWeatherService(&countingWeatherProvider{})
+	opts := scheduler.Options{Ledger: ledger}
+
+	first, err := scheduler.MailJob(context.Background(), svc, ws, opts, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first.Sent)
+	assert.Equal(t, 1, first.FailedByReason[scheduler.ReasonQueueFailed])
+
+	second, err := scheduler.MailJob(context.Background(), svc, ws, opts, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, second.Sent)
+	assert.Equal(t, 1, second.SkippedByReason[scheduler.ReasonAlreadyDelivered])
+
+	third, err := scheduler.MailJob(context.Background(), svc, ws, opts, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, third.Sent)
+	assert.Equal(t, 2, third.SkippedByReason[scheduler.ReasonAlreadyDelivered])
+	repo.AssertExpectations(t)
+
+	assert.Len(t, queued, 2)
+	for _, msg := range queued {
+		assert.Equal(t, model.OutboxWeatherUpdate, msg.Kind)
+		assert.NotNil(t, msg.DeliveryID)
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	at := time.Date(2025, 6, 1, 14, 37, 5, 0, time.FixedZone("EEST", 3*3600))
+	assert.Equal(t, time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC), scheduler.Period("hourly", at))
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), scheduler.Period("daily", at))
+}
+
+func TestMissedTicks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 20, 0, 0, time.UTC)
+	tick := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	grace := 45 * time.Minute
+	hourly := hourlyIn(time.UTC)
+
+	tests := []struct {
+		name   string
+		runs   []*model.JobRun
+		now    time.Time
+		grace  time.Duration
+		lease  time.Duration
+		missed []time.Time
+	}{
+		{name: "never ran", now: now, grace: grace, missed: []time.Time{tick}},
+		{name: "tick served", runs: []*model.JobRun{{StartedAt: tick.Add(time.Second), Status: model.JobRunSucceeded}}, now: now, grace: grace},
+		{name: "tick running elsewhere", runs: []*model.JobRun{{StartedAt: tick, Status: model.JobRunRunning}}, now: now, grace: grace, lease: 30 * time.Minute},
+		{name: "running row outlived its lease", runs: []*model.JobRun{{StartedAt: tick, Status: model.JobRunRunning}}, now: now, grace: grace, lease: 15 * time.Minute, missed: []time.Time{tick}},
+		{name: "tick interrupted", runs: []*model.JobRun{{StartedAt: tick.Add(time.Second), Status: model.JobRunInterrupted}}, now: now, grace: grace, missed: []time.Time{tick}},
+		{name: "outside grace", now: tick.Add(50 * time.Minute), grace: grace},
+		{name: "zero grace disables catch-up", now: now},
+		{
+			name:   "every tick since the last run",
+			runs:   []*model.JobRun{{StartedAt: tick.Add(-3*time.Hour + time.Minute), Status: model.JobRunSucceeded}},
+			now:    now,
+			grace:  3 * time.Hour,
+			missed: []time.Time{tick.Add(-2 * time.Hour), tick.Add(-time.Hour), tick},
+		},
+		{
+			name: "failed run does not serve earlier ticks",
+			runs: []*model.JobRun{
+				{StartedAt: tick.Add(-2*time.Hour + time.Minute), Status: model.JobRunSucceeded},
+				{StartedAt: tick.Add(time.Minute), Status: model.JobRunFailed},
+			},
+			now:    now,
+			grace:  3 * time.Hour,
+			missed: []time.Time{tick.Add(-time.Hour), tick},
+		},
+		{
+			name:   "slow run spans the top of the hour",
+			runs:   []*model.JobRun{{StartedAt: tick.Add(-time.Minute), Status: model.JobRunSucceeded}},
+			now:    now,
+			grace:  grace,
+			missed: []time.Time{tick},
+		},
+		{
+			name: "late run serves the ticks before it",
+			runs: []*model.JobRun{
+				{StartedAt: tick.Add(-50 * time.Minute), Status: model.JobRunSucceeded},
+			},
+			now:   tick.Add(-10 * time.Minute),
+			grace: 3 * time.Hour,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := scheduler.CatchUpPolicy{Schedule: hourly, Grace: tc.grace, Lease: tc.lease}
+			assert.Equal(t, tc.missed, scheduler.MissedTicks(tc.runs, tc.now, policy))
+		})
+	}
+}
+
+func TestMissedTicks_FollowsTheScheduleTimeZone(t *testing.T) {
+	policy := scheduler.CatchUpPolicy{Schedule: hourlyIn(time.FixedZone("IST", 5*3600+1800)), Grace: 45 * time.Minute}
+	now := time.Date(2025, 6, 1, 10, 10, 0, 0, time.UTC)
+	tick := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+
+	missed := scheduler.MissedTicks(nil, now, policy)
+	assert.Len(t, missed, 1)
+	assert.True(t, tick.Equal(missed[0]), "got %s", missed[0])
+
+	served := []*model.JobRun{{StartedAt: tick.Add(time.Second), Status: model.JobRunSucceeded}}
+	assert.Empty(t, scheduler.MissedTicks(served, now, policy))
+}
+
+func hourlyIn(loc *time.Location) cron.Schedule {
+	schedule, err := cron.ParseStandard(scheduler.MailJobSpec)
+	if err != nil {
+		panic(err)
+	}
+	schedule.(*cron.SpecSchedule).Location = loc
+	return schedule
+}
+
+type fakeRunLog struct {
+	runs  []*model.JobRun
+	since time.Time
+}
+
+func (f *fakeRunLog) RunsSince(ctx context.Context, name string, since time.Time) ([]*model.JobRun, error) {
+	f.since = since
+	var runs []*model.JobRun
+	for _, run := range f.runs {
+		if run.Name == name && !run.StartedAt.Before(since) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func TestCatchUp_LoadsRunsWithinGrace(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 20, 0, 0, time.UTC)
+	runs := &fakeRunLog{runs: []*model.JobRun{
+		{Name: scheduler.MailJobName, StartedAt: time.Date(2025, 6, 1, 8, 0, 1, 0, time.UTC), Status: model.JobRunSucceeded},
+		{Name: "other", StartedAt: time.Date(2025, 6, 1, 10, 0, 1, 0, time.UTC), Status: model.JobRunSucceeded},
+	}}
+
+	policy := scheduler.CatchUpPolicy{Schedule: hourlyIn(time.UTC), Grace: 2*time.Hour + 30*time.Minute}
+	missed, err := scheduler.CatchUp(context.Background(), runs, now, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 7, 50, 0, 0, time.UTC), runs.since)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+	}, missed)
+}
+
+func TestOnce(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 20, 0, 0, time.UTC)
+	s := scheduler.Once(start.Add(-time.Minute))
+	assert.Equal(t, start, s.Next(start))
+	assert.True(t, s.Next(start.Add(time.Second)).IsZero())
+}
+
+func TestMailJob_CountsCaughtUpSubscribers(t *testing.T) {
+	now := time.Now()
+	lastHour := now.Add(-time.Hour)
+	longAgo := now.Add(-5 * time.Hour)
+	subs := []*model.Subscription{
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true, LastSentAt: &lastHour},
+		{ID: 2, Email: "b@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true, LastSentAt: &longAgo},
+		{ID: 3, Email: "c@example.com", City: "Kyiv", Frequency: "daily", Confirmed: true},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	repo.On("ClaimDue", mock.Anything, mock.Anything).Return(subs, nil)
+	repo.On("UpdateWithOutbox", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+	ws := service.NewWeatherService(&countingWeatherProvider{})
+
+	summary, err := scheduler.MailJob(context.Background(), svc, ws, scheduler.Options{}, zap.NewNop())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.Sent)
+	assert.Equal(t, 1, summary.CaughtUp)
+}
+
+func TestMailJob_DryRunRendersWithoutQueueing(t *testing.T) {
+	subs := []*model.Subscription{
+		{ID: 2, Email: "b@example.com", City: "Kyiv", Frequency: "daily", Confirmed: true, UnsubscribeToken: "unsub-b"},
+		{ID: 1, Email: "a@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true, UnsubscribeToken: "unsub-a"},
+	}
+	repo := &mocks.SubscriptionRepository{}
+	repo.On("ListDue", mock.Anything, mock.MatchedBy(func(q service.DueQuery) bool {
+		return q.City == "Kyiv" && q.AfterID == 0
+	})).Return(subs, nil).Once()
+
+	history := &memoryHistory{}
+	ledger := &memoryLedger{entries: map[string]*model.Delivery{}}
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+	ws := service.NewWeatherService(&countingWeatherProvider{})
+	opts := scheduler.Options{Scope: service.Scope{City: "Kyiv"}, DryRun: true, History: history, Ledger: ledger}
+
+	summary, err := scheduler.MailJob(context.Background(), svc, ws, opts, zap.NewNop())
+
+	assert.NoError(t, err)
+	assert.True(t, summary.DryRun)
+	assert.Equal(t, 2, summary.Sent)
+	assert.Len(t, summary.Previews, 2)
+	assert.Equal(t, int64(1), summary.Previews[0].SubscriptionID)
+	assert.Equal(t, "Weather update for Kyiv", summary.Previews[0].Subject)
+	assert.Contains(t, summary.Previews[0].Body, "Weather in Kyiv")
+	assert.Contains(t, summary.Previews[0].Body, "/api/unsubscribe/unsub-a")
+	assert.Contains(t, summary.Previews[0].HTML, `href="/api/unsubscribe/unsub-a"`)
+	assert.Nil(t, subs[0].LastSentAt)
+	assert.Empty(t, history.runs)
+	assert.Empty(t, ledger.entries)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "ClaimDue", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateWithOutbox", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTrigger_ScopesManualRun(t *testing.T) {
+	repo := &mocks.SubscriptionRepository{}
+	repo.On("ClaimDue", mock.Anything, mock.MatchedBy(func(q service.ClaimQuery) bool {
+		return q.SubscriptionID == 7 && q.Owner == "w1"
+	})).Return([]*model.Subscription{}, nil).Once()
+
+	history := &memoryHistory{}
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+	ws := service.NewWeatherService(&countingWeatherProvider{})
+	locker := &fakeLocker{held: map[string]bool{}}
+	trigger := scheduler.NewTrigger(svc, ws, scheduler.Options{WorkerID: "w1", History: history}, locker, zap.NewNop())
+
+	summary, err := trigger.Run(context.Background(), service.Scope{SubscriptionID: 7}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, summary.Total)
+	assert.Equal(t, scheduler.ManualMailJobName, history.runs[0].Name)
+	assert.Equal(t, 1, locker.unlocked)
+
+	locker.held[scheduler.MailJobLockName] = true
+	_, err = trigger.Run(context.Background(), service.Scope{SubscriptionID: 7}, false)
+	assert.ErrorIs(t, err, scheduler.ErrLocked)
+	repo.AssertExpectations(t)
+}
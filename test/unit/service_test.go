@@ -1,22 +1,20 @@
 package unit
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
 	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
-	"github.com/l4ndm1nes/Weather-API-Application/pkg"
+	"github.com/l4ndm1nes/Weather-API-Application/pkg/clock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 )
 
-func init() {
-	pkg.Logger = zap.NewNop()
-}
-
 func TestSubscriptionService_Subscribe(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -57,22 +55,22 @@ func TestSubscriptionService_Subscribe(t *testing.T) {
 			mailer := &mocks.Mailer{}
 
 			if !tc.wantErr {
-				repo.On("FindByEmail", mock.Anything).Return(nil, nil).Once()
-				repo.On("Create", mock.Anything).Return(nil).Once()
-				repo.On("FindByEmail", mock.Anything).Return(&model.Subscription{
+				repo.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, nil).Once()
+				repo.On("CreateWithOutbox", mock.Anything, mock.Anything, mock.MatchedBy(func(msg *model.OutboxMessage) bool {
+					return msg.Kind == model.OutboxConfirmation && msg.Recipient == "test@unit.com" && msg.Payload.Token != ""
+				})).Return(nil).Once()
+				repo.On("FindByEmail", mock.Anything, mock.Anything).Return(&model.Subscription{
 					Email:     "test@unit.com",
 					City:      "Kyiv",
 					Frequency: "daily",
 					Confirmed: false,
 				}, nil).Once()
 			} else {
-				repo.On("FindByEmail", mock.Anything).Return(tc.findByEmailResult, tc.findByEmailErr)
-				repo.On("Create", mock.Anything).Return(tc.createErr)
+				repo.On("FindByEmail", mock.Anything, mock.Anything).Return(tc.findByEmailResult, tc.findByEmailErr)
+				repo.On("CreateWithOutbox", mock.Anything, mock.Anything, mock.Anything).Return(tc.createErr)
 			}
 
-			mailer.On("SendConfirmation", mock.Anything, mock.Anything).Return(nil)
-
-			svc := service.NewSubscriptionService(repo, mailer)
+			svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
 
 			sub := &model.Subscription{
 				Email:     "test@unit.com",
@@ -80,7 +78,7 @@ func TestSubscriptionService_Subscribe(t *testing.T) {
 				Frequency: "daily",
 			}
 
-			result, err := svc.Subscribe(sub)
+			result, err := svc.Subscribe(context.Background(), sub)
 			if tc.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tc.wantErrMessage)
@@ -148,14 +146,14 @@ func TestSubscriptionService_ConfirmSubscription(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := &mocks.SubscriptionRepository{}
 			mailer := &mocks.Mailer{}
-			svc := service.NewSubscriptionService(repo, mailer)
+			svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
 
-			repo.On("GetByToken", mock.Anything).Return(tc.getByTokenSub, tc.getByTokenErr)
+			repo.On("GetByToken", mock.Anything, mock.Anything).Return(tc.getByTokenSub, tc.getByTokenErr)
 			if tc.getByTokenErr == nil && !tc.alreadyConfirmed {
-				repo.On("Update", mock.Anything).Return(tc.updateErr)
+				repo.On("Update", mock.Anything, mock.Anything).Return(tc.updateErr)
 			}
 
-			err := svc.ConfirmSubscription("sometoken")
+			err := svc.ConfirmSubscription(context.Background(), "sometoken")
 			if tc.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tc.wantErrMessage)
@@ -180,11 +178,11 @@ func TestSubscriptionService_Unsubscribe(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := &mocks.SubscriptionRepository{}
 			mailer := &mocks.Mailer{}
-			svc := service.NewSubscriptionService(repo, mailer)
+			svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
 
-			repo.On("UnsubscribeByToken", mock.Anything).Return(tc.err)
+			repo.On("UnsubscribeByToken", mock.Anything, mock.Anything).Return(tc.err)
 
-			err := svc.Unsubscribe("token")
+			err := svc.Unsubscribe(context.Background(), "token")
 			if tc.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -219,11 +217,11 @@ func TestSubscriptionService_GetAllConfirmed(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := &mocks.SubscriptionRepository{}
 			mailer := &mocks.Mailer{}
-			svc := service.NewSubscriptionService(repo, mailer)
+			svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
 
-			repo.On("GetAllConfirmed").Return(tc.returned, tc.repoErr)
+			repo.On("GetAllConfirmed", mock.Anything).Return(tc.returned, tc.repoErr)
 
-			subs, err := svc.GetAllConfirmed()
+			subs, err := svc.GetAllConfirmed(context.Background())
 			if tc.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, subs)
@@ -235,6 +233,51 @@ func TestSubscriptionService_GetAllConfirmed(t *testing.T) {
 	}
 }
 
+func TestSubscriptionService_ListDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mocks.SubscriptionRepository{}
+	mailer := &mocks.Mailer{}
+	svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
+
+	want := service.DueQuery{
+		HourlySentBefore: now,
+		DailySentBefore:  now.Add(-23 * time.Hour),
+		AfterID:          42,
+		Limit:            100,
+		Scope:            service.Scope{City: "Kyiv"},
+	}
+	returned := []*model.Subscription{{ID: 43, Email: "1@mail.com"}}
+	repo.On("ListDue", mock.Anything, want).Return(returned, nil)
+
+	subs, err := svc.ListDue(context.Background(), now, 42, 100, service.Scope{City: "Kyiv"})
+	assert.NoError(t, err)
+	assert.Equal(t, returned, subs)
+}
+
+func TestSubscriptionService_ClaimDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mocks.SubscriptionRepository{}
+	mailer := &mocks.Mailer{}
+	svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
+
+	want := service.ClaimQuery{
+		DueQuery: service.DueQuery{
+			HourlySentBefore: now,
+			DailySentBefore:  now.Add(-23 * time.Hour),
+			Limit:            100,
+		},
+		Owner: "worker-1",
+		Now:   now,
+		Until: now.Add(15 * time.Minute),
+	}
+	repo.On("ClaimDue", mock.Anything, want).Return([]*model.Subscription{}, nil)
+
+	subs, err := svc.ClaimDue(context.Background(), now, 0, 100, service.Scope{}, "worker-1", 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, subs)
+	repo.AssertExpectations(t)
+}
+
 func TestSubscriptionService_Update(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -249,11 +292,11 @@ func TestSubscriptionService_Update(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := &mocks.SubscriptionRepository{}
 			mailer := &mocks.Mailer{}
-			svc := service.NewSubscriptionService(repo, mailer)
+			svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
 
-			repo.On("Update", mock.Anything).Return(tc.repoErr)
+			repo.On("Update", mock.Anything, mock.Anything).Return(tc.repoErr)
 
-			err := svc.Update(&model.Subscription{})
+			err := svc.Update(context.Background(), &model.Subscription{})
 			if tc.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -277,11 +320,13 @@ func TestSubscriptionService_SendWeatherUpdate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := &mocks.SubscriptionRepository{}
 			mailer := &mocks.Mailer{}
-			svc := service.NewSubscriptionService(repo, mailer)
+			svc := service.NewSubscriptionService(repo, mailer, zap.NewNop())
 
-			mailer.On("SendWeatherUpdate", mock.Anything, "", mock.Anything).Return(tc.mailErr)
+			sub := &model.Subscription{Email: "test@unit.com", City: "Kyiv", UnsubscribeToken: "unsub"}
+			update := model.WeatherUpdate{City: "Kyiv", Weather: model.Weather{Temperature: 20}, UnsubscribeToken: "unsub"}
+			mailer.On("SendWeatherUpdate", mock.Anything, "test@unit.com", update).Return(tc.mailErr)
 
-			err := svc.SendWeatherUpdate("test@unit.com", "weather info")
+			err := svc.SendWeatherUpdate(context.Background(), sub, &model.Weather{Temperature: 20})
 			if tc.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -290,3 +335,58 @@ func TestSubscriptionService_SendWeatherUpdate(t *testing.T) {
 		})
 	}
 }
+
+type countingWeatherProvider struct {
+	calls int
+}
+
+func (p *countingWeatherProvider) GetWeather(ctx context.Context, city string) (*model.Weather, error) {
+	p.calls++
+	if city == "Atlantis" {
+		return nil, errors.New("city not found")
+	}
+	return &model.Weather{Temperature: 10, Humidity: 50, Description: "Cloudy"}, nil
+}
+
+func TestWeatherService_Cache(t *testing.T) {
+	provider := &countingWeatherProvider{}
+	ws := service.NewCachedWeatherService(provider, time.Minute, 0)
+
+	for i := 0; i < 3; i++ {
+		w, err := ws.GetWeather(context.Background(), "Kyiv")
+		assert.NoError(t, err)
+		assert.Equal(t, "Cloudy", w.Description)
+	}
+	_, err := ws.GetWeather(context.Background(), " kyiv ")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, provider.calls)
+
+	_, err = ws.GetWeather(context.Background(), "Atlantis")
+	assert.Error(t, err)
+	_, err = ws.GetWeather(context.Background(), "Atlantis")
+	assert.Error(t, err)
+	assert.Equal(t, 3, provider.calls)
+}
+
+func TestWeatherService_CacheIsBounded(t *testing.T) {
+	provider := &countingWeatherProvider{}
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	ws := service.NewCachedWeatherService(provider, time.Minute, 2)
+	ws.Clock = clk
+
+	for _, city := range []string{"Kyiv", "Lviv", "Odesa", "Dnipro"} {
+		_, err := ws.GetWeather(context.Background(), city)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, ws.CacheLen())
+
+	// Kyiv was evicted, Dnipro is still cached.
+	_, _ = ws.GetWeather(context.Background(), "Dnipro")
+	assert.Equal(t, 4, provider.calls)
+	_, _ = ws.GetWeather(context.Background(), "Kyiv")
+	assert.Equal(t, 5, provider.calls)
+
+	clk.Advance(2 * time.Minute)
+	_, _ = ws.GetWeather(context.Background(), "Dnipro")
+	assert.Equal(t, 6, provider.calls)
+}
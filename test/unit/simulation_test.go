@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/simulation"
+	"github.com/stretchr/testify/assert"
+)
+
+func simulatedSubscriptions() []*model.Subscription {
+	return []*model.Subscription{
+		{Email: "hourly@example.com", City: "Kyiv", Frequency: "hourly", Confirmed: true, UnsubscribeToken: "u1"},
+		{Email: "daily@example.com", City: "Lviv", Frequency: "daily", Confirmed: true, UnsubscribeToken: "u2"},
+		{Email: "pending@example.com", City: "Kyiv", Frequency: "hourly", UnsubscribeToken: "u3"},
+	}
+}
+
+func TestSimulate_Week(t *testing.T) {
+	start := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	report, err := simulation.Simulate(context.Background(), simulation.Config{
+		Start:         start,
+		Duration:      7 * 24 * time.Hour,
+		Subscriptions: simulatedSubscriptions(),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, report.Runs, 168)
+	counts := report.PerRecipient()
+	assert.Equal(t, 168, counts["hourly@example.com"])
+	assert.Equal(t, 7, counts["daily@example.com"])
+	assert.Zero(t, counts["pending@example.com"])
+
+	var daily []time.Time
+	for _, e := range report.Emails {
+		if e.Recipient == "daily@example.com" {
+			daily = append(daily, e.At)
+		}
+	}
+	for i, at := range daily {
+		assert.Equal(t, start.Add(time.Duration(i)*24*time.Hour), at)
+	}
+}
+
+func TestSimulate_CatchesUpAfterOutage(t *testing.T) {
+	start := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	down := start.Add(34 * time.Hour)
+	report, err := simulation.Simulate(context.Background(), simulation.Config{
+		Start:         start,
+		Duration:      7 * 24 * time.Hour,
+		Subscriptions: simulatedSubscriptions(),
+		Outages:       []simulation.Outage{{From: down, To: down.Add(20 * time.Minute)}},
+		CatchUpGrace:  45 * time.Minute,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 168, report.PerRecipient()["hourly@example.com"])
+	var caughtUp []time.Time
+	for _, r := range report.Runs {
+		if r.CatchUp {
+			caughtUp = append(caughtUp, r.At)
+		}
+	}
+	assert.Equal(t, []time.Time{down.Add(20 * time.Minute)}, caughtUp)
+
+	report, err = simulation.Simulate(context.Background(), simulation.Config{
+		Start:         start,
+		Duration:      7 * 24 * time.Hour,
+		Subscriptions: simulatedSubscriptions(),
+		Outages:       []simulation.Outage{{From: down, To: down.Add(time.Hour / 2)}},
+		CatchUpGrace:  20 * time.Minute,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 167, report.PerRecipient()["hourly@example.com"], "a restart past the grace waits for the next tick")
+}
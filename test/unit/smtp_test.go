@@ -0,0 +1,231 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/mail"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTP is a minimal SMTP server. It rejects recipients starting with
+// "reject" for good, "busy" for now and "relay" by policy and, with hangUp
+// set, drops the connection after every message.
+type fakeSMTP struct {
+	ln     net.Listener
+	hangUp bool
+
+	mu          sync.Mutex
+	connections int
+	resets      int
+	messages    []string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) port() string {
+	return strings.TrimPrefix(s.ln.Addr().String(), "127.0.0.1:")
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.connections++
+		s.mu.Unlock()
+		go s.handle(c)
+	}
+}
+
+func (s *fakeSMTP) handle(c net.Conn) {
+	defer c.Close()
+	tp := textproto.NewConn(c)
+	_ = tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO":
+			_ = tp.PrintfLine("250-fake\r\n250 8BITMIME")
+		case "RSET":
+			s.mu.Lock()
+			s.resets++
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			switch {
+			case strings.Contains(line, "<reject"):
+				_ = tp.PrintfLine("550 5.1.1 no such user")
+				continue
+			case strings.Contains(line, "<busy"):
+				_ = tp.PrintfLine("451 4.2.0 mailbox busy, try later")
+				continue
+			case strings.Contains(line, "<relay"):
+				_ = tp.PrintfLine("550 5.7.1 relaying denied")
+				continue
+			}
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			body, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(body))
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 queued")
+			if s.hangUp {
+				return
+			}
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTP) stats() (connections, resets, messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, s.resets, len(s.messages)
+}
+
+func newTestPool(t *testing.T, srv *fakeSMTP, mode string) *mail.Pool {
+	pool, err := mail.NewPool(mail.Config{Host: "127.0.0.1", Port: srv.port(), TLSMode: mode, PoolSize: 1}, nil)
+	assert.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestPool_ReusesConnectionWithReset(t *testing.T) {
+	srv := newFakeSMTP(t)
+	pool := newTestPool(t, srv, mail.TLSNone)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, pool.Send(context.Background(), "from@example.com", []string{"to@example.com"}, []byte("Subject: hi\r\n\r\nhello\r\n")))
+	}
+
+	connections, resets, messages := srv.stats()
+	assert.Equal(t, 1, connections)
+	assert.Equal(t, 2, resets)
+	assert.Equal(t, 3, messages)
+}
+
+func TestPool_ReconnectsAfterServerHangsUp(t *testing.T) {
+	srv := newFakeSMTP(t)
+	srv.hangUp = true
+	pool := newTestPool(t, srv, mail.TLSAuto)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, pool.Send(context.Background(), "from@example.com", []string{"to@example.com"}, []byte("hello\r\n")))
+	}
+
+	connections, _, messages := srv.stats()
+	assert.Equal(t, 3, connections)
+	assert.Equal(t, 3, messages)
+}
+
+func TestPool_KeepsConnectionAfterRejectedRecipient(t *testing.T) {
+	srv := newFakeSMTP(t)
+	pool := newTestPool(t, srv, mail.TLSNone)
+
+	err := pool.Send(context.Background(), "from@example.com", []string{"reject@example.com"}, []byte("hello\r\n"))
+	var reply *mail.SMTPError
+	assert.True(t, errors.As(err, &reply))
+	assert.Equal(t, 550, reply.Code)
+
+	assert.NoError(t, pool.Send(context.Background(), "from@example.com", []string{"to@example.com"}, []byte("hello\r\n")))
+	connections, resets, messages := srv.stats()
+	assert.Equal(t, 1, connections)
+	assert.Equal(t, 1, resets)
+	assert.Equal(t, 1, messages)
+}
+
+func TestPool_RequiresStartTLS(t *testing.T) {
+	srv := newFakeSMTP(t)
+	pool := newTestPool(t, srv, mail.TLSStartTLS)
+
+	err := pool.Send(context.Background(), "from@example.com", []string{"to@example.com"}, []byte("hello\r\n"))
+
+	assert.ErrorContains(t, err, "does not offer STARTTLS")
+	_, _, messages := srv.stats()
+	assert.Equal(t, 0, messages)
+}
+
+func TestNewPool_RejectsBadTLSSettings(t *testing.T) {
+	_, err := mail.NewPool(mail.Config{Host: "smtp.example.com", Port: "587", TLSMode: "sometimes"}, nil)
+	assert.Error(t, err)
+
+	_, err = mail.NewPool(mail.Config{Host: "smtp.example.com", Port: "587", CAFile: "testdata/missing.pem"}, nil)
+	assert.Error(t, err)
+}
+
+func TestSMTPMailer_SendsOverPool(t *testing.T) {
+	srv := newFakeSMTP(t)
+	mailer := mail.NewSMTPMailer(newTestPool(t, srv, mail.TLSNone), "from@example.com", "https://weather.example.com", nil)
+
+	assert.NoError(t, mailer.SendConfirmation(context.Background(), "to@example.com", "tok", "en"))
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Len(t, srv.messages, 1)
+	body := srv.messages[0]
+	assert.Contains(t, body, "To: to@example.com")
+	assert.Contains(t, body, "/api/confirm/tok")
+}
+
+func TestPool_ClassifiesReplies(t *testing.T) {
+	srv := newFakeSMTP(t)
+	pool := newTestPool(t, srv, mail.TLSNone)
+	send := func(to string) *mail.SMTPError {
+		err := pool.Send(context.Background(), "from@example.com", []string{to}, []byte("hello\r\n"))
+		var reply *mail.SMTPError
+		assert.True(t, errors.As(err, &reply), to)
+		return reply
+	}
+
+	rejected := send("reject@example.com")
+	assert.Equal(t, mail.CommandRcpt, rejected.Command)
+	assert.Equal(t, "5.1.1", rejected.EnhancedCode)
+	assert.True(t, rejected.Permanent())
+	assert.True(t, rejected.RecipientRejected())
+
+	busy := send("busy@example.com")
+	assert.True(t, busy.Temporary())
+	assert.False(t, busy.Permanent())
+
+	relay := send("relay@example.com")
+	assert.True(t, relay.Permanent())
+	assert.False(t, relay.RecipientRejected())
+}
+
+func TestSMTPError_MailFromIsRetried(t *testing.T) {
+	err := &mail.SMTPError{Command: mail.CommandMail, Code: 550, EnhancedCode: "5.7.1", Message: "5.7.1 sender rejected"}
+
+	assert.False(t, err.Permanent())
+	assert.False(t, err.RecipientRejected())
+	assert.Equal(t, "smtp MAIL: 550 5.7.1 sender rejected", err.Error())
+}
@@ -0,0 +1,201 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/outbox"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type memorySuppressions struct {
+	reasons map[string]string
+}
+
+func (s *memorySuppressions) Suppress(ctx context.Context, sup *model.Suppression) error {
+	if _, ok := s.reasons[sup.Email]; !ok {
+		s.reasons[sup.Email] = sup.Reason
+	}
+	return nil
+}
+
+func (s *memorySuppressions) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	_, ok := s.reasons[email]
+	return ok, nil
+}
+
+func TestSuppressionService_Process(t *testing.T) {
+	store := &memorySuppressions{reasons: map[string]string{}}
+	svc := service.NewSuppressionService(store, zap.NewNop())
+
+	n, err := svc.Process(context.Background(), "generic", []model.EmailEvent{
+		{Type: model.EmailEventBounce, Email: "hard@example.com"},
+		{Type: model.EmailEventBounce, Email: "soft@example.com", Soft: true},
+		{Type: model.EmailEventComplaint, Email: "spam@example.com"},
+		{Type: "delivered", Email: "ok@example.com"},
+		{Type: model.EmailEventBounce},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, map[string]string{
+		"hard@example.com": model.EmailEventBounce,
+		"spam@example.com": model.EmailEventComplaint,
+	}, store.reasons)
+}
+
+func TestSubscriptionService_Subscribe_Suppressed(t *testing.T) {
+	repo := &mocks.SubscriptionRepository{}
+	svc := service.NewSubscriptionService(repo, nil, zap.NewNop())
+	svc.Suppressions = &memorySuppressions{reasons: map[string]string{"gone@example.com": model.EmailEventBounce}}
+
+	_, err := svc.Subscribe(context.Background(), &model.Subscription{Email: "gone@example.com", City: "Kyiv", Frequency: "daily"})
+
+	assert.ErrorIs(t, err, service.ErrSuppressed)
+	repo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+}
+
+func TestSubscriptionHandler_SuppressedLooksLikeInvalidInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	subMock := &mocks.SubscriptionService{}
+	subMock.On("Subscribe", mock.Anything, mock.Anything).Return(nil, service.ErrSuppressed).Once()
+	r := gin.New()
+	handler.RegisterRoutes(r, handler.NewSubscriptionHandler(subMock, nil, zap.NewNop()))
+
+	form := url.Values{"email": {"gone@example.com"}, "city": {"Kyiv"}, "frequency": {"daily"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/subscribe", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Invalid input"}`, w.Body.String())
+}
+
+func TestDispatcher_DeadLettersSuppressedRecipients(t *testing.T) {
+	store := &memoryOutbox{
+		messages: []*model.OutboxMessage{
+			{ID: 1, Kind: model.OutboxConfirmation, Recipient: "gone@example.com", Payload: model.OutboxPayload{Token: "tok"}, Status: model.OutboxPending},
+			{ID: 2, Kind: model.OutboxConfirmation, Recipient: "ok@example.com", Payload: model.OutboxPayload{Token: "tok"}, Status: model.OutboxPending},
+		},
+		retries: map[int64]time.Time{},
+	}
+	mailer := &mocks.Mailer{}
+	mailer.On("SendConfirmation", mock.Anything, "ok@example.com", "tok", "").Return(nil).Once()
+	suppressions := &memorySuppressions{reasons: map[string]string{"gone@example.com": model.EmailEventComplaint}}
+
+	d := outbox.NewDispatcher(store, mailer, nil, outbox.Config{MaxAttempts: 5, Suppressions: suppressions}, zap.NewNop())
+	_, err := d.DispatchOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.OutboxDead, store.find(1).Status)
+	assert.Equal(t, model.OutboxSent, store.find(2).Status)
+	mailer.AssertExpectations(t)
+}
+
+func setupEmailEventsRouter(token string, store *memorySuppressions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := service.NewSuppressionService(store, zap.NewNop())
+	handler.RegisterEmailEventRoutes(r, token, handler.NewEmailEventsHandler(svc, zap.NewNop()))
+	return r
+}
+
+func TestEmailEventsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		path       string
+		auth       string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantEmails []string
+	}{
+		{
+			name:       "generic",
+			token:      "secret",
+			path:       "/api/email-events/generic",
+			auth:       "Bearer secret",
+			body:       `[{"type":"bounce","email":"hard@example.com","reason":"550 5.1.1"},{"type":"bounce","email":"soft@example.com","bounce_type":"soft"},{"type":"complaint","email":"spam@example.com"}]`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"received":3,"suppressed":2}`,
+			wantEmails: []string{"hard@example.com", "spam@example.com"},
+		},
+		{
+			name:       "sendgrid with query token",
+			token:      "secret",
+			path:       "/api/email-events/sendgrid?token=secret",
+			body:       `[{"event":"bounce","email":"hard@example.com","type":"bounce"},{"event":"bounce","email":"blocked@example.com","type":"blocked"},{"event":"spamreport","email":"spam@example.com"},{"event":"delivered","email":"ok@example.com"}]`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"received":3,"suppressed":2}`,
+			wantEmails: []string{"hard@example.com", "spam@example.com"},
+		},
+		{
+			name:       "wrong token",
+			token:      "secret",
+			path:       "/api/email-events/generic?token=guess",
+			body:       `[{"type":"bounce","email":"hard@example.com"}]`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "disabled without token",
+			path:       "/api/email-events/generic",
+			auth:       "Bearer ",
+			body:       `[]`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unknown provider",
+			token:      "secret",
+			path:       "/api/email-events/mailchimp",
+			auth:       "Bearer secret",
+			body:       `[]`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "malformed body",
+			token:      "secret",
+			path:       "/api/email-events/generic",
+			auth:       "Bearer secret",
+			body:       `{"type":"bounce"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &memorySuppressions{reasons: map[string]string{}}
+			r := setupEmailEventsRouter(tc.token, store)
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantBody != "" {
+				assert.JSONEq(t, tc.wantBody, w.Body.String())
+			}
+			var emails []string
+			for email := range store.reasons {
+				emails = append(emails, email)
+			}
+			assert.ElementsMatch(t, tc.wantEmails, emails)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/i18n"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/templates"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender_AllEmails(t *testing.T) {
+	weather := model.Weather{Temperature: 21.5, Humidity: 40, Description: "Sunny"}
+	tests := []struct {
+		name    string
+		data    any
+		subject string
+		text    string
+		html    string
+	}{
+		{templates.Confirmation, templates.ConfirmationData{BaseURL: "http://x", Token: "tok"}, "Confirm your weather subscription", "http://x/api/confirm/tok", `href="http://x/api/confirm/tok"`},
+		{templates.WeatherUpdate, templates.WeatherUpdateData{BaseURL: "http://x", City: "Kyiv", Weather: weather, UnsubscribeToken: "u"}, "Weather update for Kyiv", "Temperature: 21.5°C", `href="http://x/api/unsubscribe/u"`},
+		{templates.Alert, templates.AlertData{BaseURL: "http://x", City: "Kyiv", Weather: weather, Reason: "Heat wave", UnsubscribeToken: "u"}, "Weather alert for Kyiv", "Heat wave", "Heat wave"},
+		{templates.Goodbye, templates.GoodbyeData{BaseURL: "http://x", City: "Kyiv"}, "You have unsubscribed from weather updates", "for Kyiv", `href="http://x/"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			email, err := templates.Render(i18n.English, tc.name, tc.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.subject, email.Subject)
+			assert.Contains(t, email.Text, tc.text)
+			assert.Contains(t, email.HTML, tc.html)
+		})
+	}
+
+	_, err := templates.Render(i18n.English, "newsletter", nil)
+	assert.Error(t, err)
+}
+
+func TestRender_EscapesHTML(t *testing.T) {
+	email, err := templates.Render(i18n.English, templates.WeatherUpdate, templates.WeatherUpdateData{City: "<b>Kyiv</b>"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, email.HTML, "&lt;b&gt;Kyiv&lt;/b&gt;")
+	assert.Contains(t, email.Text, "Weather in <b>Kyiv</b>")
+}
+
+func TestEmail_MIME(t *testing.T) {
+	email, err := templates.Render(i18n.English, templates.WeatherUpdate, templates.WeatherUpdateData{
+		BaseURL:          "http://x",
+		City:             "Київ",
+		Weather:          model.Weather{Temperature: -3, Humidity: 90, Description: "Snow"},
+		UnsubscribeToken: "u",
+	})
+	assert.NoError(t, err)
+
+	raw, err := email.MIME("weather@example.com", "a@example.com", time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	assert.NoError(t, err)
+	assert.NotContains(t, msg.Header.Get("Subject"), "Київ", "non-ASCII subject must be encoded")
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Weather update for Київ", subject)
+	assert.Equal(t, "Mon, 06 Jan 2025 08:00:00 +0000", msg.Header.Get("Date"))
+	assert.Empty(t, msg.Header.Get("List-Unsubscribe"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		body, err := io.ReadAll(part)
+		assert.NoError(t, err)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, types)
+	assert.Contains(t, bodies[0], "Weather in Київ")
+	assert.Contains(t, bodies[0], "Temperature: -3.0°C")
+	assert.Contains(t, bodies[1], "<h2 style=\"margin: 0 0 8px;\">Weather in Київ</h2>")
+}
+
+func TestEmail_MIMEListUnsubscribe(t *testing.T) {
+	email := &templates.Email{Subject: "Weather", Text: "text", HTML: "<p>html</p>", ListUnsubscribe: "https://weather.example.com/api/unsubscribe/u"}
+
+	raw, err := email.MIME("weather@example.com", "a@example.com", time.Now())
+	assert.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	assert.NoError(t, err)
+	assert.Equal(t, "<https://weather.example.com/api/unsubscribe/u>", msg.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+}
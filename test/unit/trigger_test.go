@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/handler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/scheduler"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeTrigger struct {
+	scope  service.Scope
+	dryRun bool
+	err    error
+}
+
+func (f *fakeTrigger) Run(ctx context.Context, scope service.Scope, dryRun bool) (*scheduler.Summary, error) {
+	f.scope, f.dryRun = scope, dryRun
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &scheduler.Summary{Total: 1, Sent: 1, DryRun: dryRun, Previews: []scheduler.Preview{{SubscriptionID: 7, Body: "Hello!"}}}, nil
+}
+
+func setupTriggerRouter(trigger handler.MailJobTrigger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	admin := handler.RegisterAdminRoutes(r, "secret", handler.NewAdminHandler(nil, zap.NewNop()))
+	handler.RegisterTriggerRoutes(admin, handler.NewTriggerHandler(trigger, zap.NewNop()))
+	return r
+}
+
+func TestTriggerHandler_RunMailJob(t *testing.T) {
+	trigger := &fakeTrigger{}
+	r := setupTriggerRouter(trigger)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("POST", "/admin/jobs/mail_job/run"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, service.Scope{}, trigger.scope)
+	assert.False(t, trigger.dryRun)
+
+	req := httptest.NewRequest("POST", "/admin/jobs/mail_job/run", strings.NewReader(`{"city":"Kyiv","subscription_id":7,"dry_run":true}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, service.Scope{City: "Kyiv", SubscriptionID: 7}, trigger.scope)
+	assert.True(t, trigger.dryRun)
+	var body struct {
+		Summary scheduler.Summary `json:"summary"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Summary.DryRun)
+	assert.Equal(t, "Hello!", body.Summary.Previews[0].Body)
+
+	trigger.err = scheduler.ErrLocked
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("POST", "/admin/jobs/mail_job/run"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req = httptest.NewRequest("POST", "/admin/jobs/mail_job/run", strings.NewReader(`{"subscription_id":"x"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
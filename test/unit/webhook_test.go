@@ -0,0 +1,166 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/l4ndm1nes/Weather-API-Application/internal/adapter/channel"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/mocks"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/model"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/notify"
+	"github.com/l4ndm1nes/Weather-API-Application/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// webhookReceiver is a receiver that answers challenges, keeps the secret it
+// is handed and checks the signature of every later delivery.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	echo     bool
+	events   []map[string]any
+	verified []bool
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var event map[string]any
+	_ = json.Unmarshal(body, &event)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if event["type"] == channel.WebhookEventChallenge {
+		rcv.secret, _ = event["secret"].(string)
+	}
+	signature := channel.Sign(rcv.secret, r.Header.Get(channel.TimestampHeader), body)
+	rcv.verified = append(rcv.verified, signature == r.Header.Get(channel.SignatureHeader))
+	rcv.events = append(rcv.events, event)
+
+	if event["type"] == channel.WebhookEventChallenge {
+		challenge := event["challenge"]
+		if !rcv.echo {
+			challenge = "wrong"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"challenge": challenge})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		channel.Sign("secret", "1700000000", []byte("{}")),
+	)
+}
+
+func TestWebhookNotifier_ChallengeAndSignedDelivery(t *testing.T) {
+	rcv := &webhookReceiver{echo: true}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	registry := notify.NewRegistry("https://weather.example.com")
+	registry.Register(model.ChannelWebhook, channel.NewWebhookNotifier(srv.URL, nil))
+	target := notify.Target{Channel: model.ChannelWebhook, Destination: srv.URL + "/hook", Secret: "s3cret"}
+
+	assert.NoError(t, registry.Verify(context.Background(), target))
+	assert.NoError(t, registry.Notify(context.Background(), target, kyivUpdate))
+
+	assert.Equal(t, "s3cret", rcv.secret)
+	assert.Equal(t, []bool{true, true}, rcv.verified)
+	assert.Equal(t, channel.WebhookEventWeatherUpdate, rcv.events[1]["type"])
+	assert.Equal(t, "Kyiv", rcv.events[1]["city"])
+}
+
+func TestWebhookNotifier_RejectsWrongChallenge(t *testing.T) {
+	srv := httptest.NewServer(&webhookReceiver{})
+	defer srv.Close()
+	registry := notify.NewRegistry("")
+	registry.Register(model.ChannelWebhook, channel.NewWebhookNotifier(srv.URL, nil))
+
+	err := registry.Verify(context.Background(), notify.Target{Channel: model.ChannelWebhook, Destination: srv.URL + "/hook", Secret: "s3cret"})
+
+	assert.ErrorIs(t, err, notify.ErrVerificationFailed)
+}
+
+func TestWebhookNotifier_RejectsNonPublicDestinations(t *testing.T) {
+	notifier := channel.NewWebhookNotifier("", nil)
+
+	for _, dest := range []string{
+		"https://localhost/hook",
+		"https://127.0.0.1/hook",
+		"https://10.0.0.5/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hook",
+		"https://100.100.100.200/hook",
+	} {
+		assert.Error(t, notifier.ValidateDestination(dest), dest)
+	}
+	assert.NoError(t, notifier.ValidateDestination("https://hooks.example.com/weather"))
+}
+
+func TestWebhookNotifier_RefusesToDialNonPublicAddresses(t *testing.T) {
+	rcv := &webhookReceiver{echo: true}
+	srv := httptest.NewTLSServer(rcv)
+	defer srv.Close()
+	notifier := channel.NewWebhookNotifier("", nil)
+
+	err := notifier.VerifyDestination(context.Background(), srv.URL+"/hook", "s3cret")
+
+	assert.ErrorContains(t, err, "not a public address")
+	assert.Empty(t, rcv.events)
+}
+
+func TestWebhookHandshakeWaitsForConfirmation(t *testing.T) {
+	rcv := &webhookReceiver{echo: true}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	registry := notify.NewRegistry("")
+	registry.Register(model.ChannelWebhook, channel.NewWebhookNotifier(srv.URL, nil))
+
+	repo := &mocks.SubscriptionRepository{}
+	repo.On("FindByEmail", mock.Anything, "hook@example.com").Return(nil, service.ErrNotFound).Once()
+	repo.On("CreateWithOutbox", mock.Anything, mock.MatchedBy(func(sub *model.Subscription) bool {
+		return len(sub.WebhookSecret) == 64
+	}), mock.Anything).Return(nil).Once()
+	repo.On("FindByEmail", mock.Anything, "hook@example.com").Return(&model.Subscription{Email: "hook@example.com"}, nil).Once()
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+	svc.Channels = registry
+	sub := &model.Subscription{Email: "hook@example.com", City: "Kyiv", Frequency: "daily", Channel: model.ChannelWebhook, Destination: srv.URL + "/hook"}
+
+	_, err := svc.Subscribe(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Empty(t, rcv.events, "subscribing must not contact the destination")
+
+	repo.On("GetByToken", mock.Anything, "confirm-tok").Return(sub, nil).Once()
+	repo.On("Update", mock.Anything, sub).Return(nil).Once()
+
+	assert.NoError(t, svc.ConfirmSubscription(context.Background(), "confirm-tok"))
+	assert.Equal(t, sub.WebhookSecret, rcv.secret)
+	repo.AssertExpectations(t)
+}
+
+func TestConfirmSubscription_FailedHandshakeKeepsSubscriptionUnconfirmed(t *testing.T) {
+	srv := httptest.NewServer(&webhookReceiver{})
+	defer srv.Close()
+	registry := notify.NewRegistry("")
+	registry.Register(model.ChannelWebhook, channel.NewWebhookNotifier(srv.URL, nil))
+
+	repo := &mocks.SubscriptionRepository{}
+	sub := &model.Subscription{Email: "hook@example.com", Channel: model.ChannelWebhook, Destination: srv.URL + "/hook", WebhookSecret: "s3cret"}
+	repo.On("GetByToken", mock.Anything, "confirm-tok").Return(sub, nil).Once()
+	svc := service.NewSubscriptionService(repo, &mocks.Mailer{}, zap.NewNop())
+	svc.Channels = registry
+
+	err := svc.ConfirmSubscription(context.Background(), "confirm-tok")
+
+	assert.ErrorIs(t, err, notify.ErrVerificationFailed)
+	assert.False(t, sub.Confirmed)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}